@@ -0,0 +1,100 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControllerConfigSpec overrides the controller configuration given by flags.
+// Empty fields keep the flag value.
+type ControllerConfigSpec struct {
+	// DefaultGateway is the Gateway name used when a Service does not set one.
+	// +optional
+	DefaultGateway string `json:"defaultGateway,omitempty"`
+
+	// DefaultGatewayNamespace is the Gateway namespace used when a Service does not set one.
+	// +optional
+	DefaultGatewayNamespace string `json:"defaultGatewayNamespace,omitempty"`
+
+	// DefaultSectionName is the listener used when a Service does not set one.
+	// +optional
+	DefaultSectionName string `json:"defaultSectionName,omitempty"`
+
+	// GatewayNamespaces lists additional namespaces whose Gateways are
+	// considered for automatic listener selection.
+	// +optional
+	GatewayNamespaces []string `json:"gatewayNamespaces,omitempty"`
+
+	// HostnameTemplate derives the hostname of Services without a hostname
+	// annotation, e.g. {{.Name}}.{{.Namespace}}.apps.example.com.
+	// +optional
+	HostnameTemplate string `json:"hostnameTemplate,omitempty"`
+
+	// ClusterName is available to hostname templates as {{.ClusterName}}.
+	// +optional
+	ClusterName string `json:"clusterName,omitempty"`
+
+	// AllowedHostnames restricts the hostnames Services in any namespace may
+	// claim, in addition to HostnamePolicies and Namespace annotations.
+	// +optional
+	AllowedHostnames []string `json:"allowedHostnames,omitempty"`
+
+	// DeniedHostnames lists hostnames no Service may claim.
+	// +optional
+	DeniedHostnames []string `json:"deniedHostnames,omitempty"`
+
+	// DisableFinalizer stops adding the finalizer to exposed Services.
+	// +optional
+	DisableFinalizer *bool `json:"disableFinalizer,omitempty"`
+}
+
+// ControllerConfigStatus reports the configuration in effect.
+type ControllerConfigStatus struct {
+	// ObservedGeneration is the generation last validated by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Active is the configuration in effect: the last valid spec merged with the flag values.
+	// +optional
+	Active *ControllerConfigSpec `json:"active,omitempty"`
+
+	// Conditions report whether the spec is valid and applied.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Valid",type="string",JSONPath=".status.conditions[?(@.type=='Valid')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ControllerConfig configures the controller at runtime. The controller reads
+// the ControllerConfig named by its --controller-config flag.
+type ControllerConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ControllerConfigSpec   `json:"spec,omitempty"`
+	Status ControllerConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ControllerConfigList contains a list of ControllerConfig.
+type ControllerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControllerConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ControllerConfig{}, &ControllerConfigList{})
+}
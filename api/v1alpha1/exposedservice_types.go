@@ -0,0 +1,130 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ExposedServiceSpec describes the HTTPRoute generated for a Service.
+type ExposedServiceSpec struct {
+	// ServiceName is the Service in the same namespace traffic is sent to.
+	// +kubebuilder:validation:MinLength=1
+	ServiceName string `json:"serviceName"`
+
+	// Port is the Service port traffic is sent to. Defaults to the first port.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// Hostnames are the hostnames the route matches.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	Hostnames []gatewayv1.Hostname `json:"hostnames"`
+
+	// GatewayRefs are the Gateway listeners the route attaches to. They must
+	// share a namespace, which is where the route is created. Defaults to the
+	// controller's default Gateway and listener.
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:XValidation:rule="self.all(r, (has(r.namespace) ? r.namespace : '') == (has(self[0].namespace) ? self[0].namespace : ''))",message="all gatewayRefs must be in the same namespace"
+	GatewayRefs []GatewayReference `json:"gatewayRefs,omitempty"`
+
+	// Rules match requests and apply filters before sending them to the
+	// Service. Defaults to a single rule matching every request.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Rules []ExposedServiceRule `json:"rules,omitempty"`
+
+	// SkipReferenceGrant skips creating the ReferenceGrant, e.g. when it is
+	// managed separately.
+	// +optional
+	SkipReferenceGrant bool `json:"skipReferenceGrant,omitempty"`
+}
+
+// GatewayReference identifies a Gateway listener.
+type GatewayReference struct {
+	// Name is the Gateway name.
+	Name gatewayv1.ObjectName `json:"name"`
+
+	// Namespace is the Gateway namespace. Defaults to the controller's
+	// default Gateway namespace.
+	// +optional
+	Namespace gatewayv1.Namespace `json:"namespace,omitempty"`
+
+	// SectionName is the listener name. Defaults to the controller's default
+	// listener.
+	// +optional
+	SectionName gatewayv1.SectionName `json:"sectionName,omitempty"`
+}
+
+// ExposedServiceRule is a rule of the generated HTTPRoute.
+type ExposedServiceRule struct {
+	// Matches are the conditions a request must meet, any of which selects
+	// the rule. Defaults to matching every path.
+	// +optional
+	// +kubebuilder:validation:MaxItems=64
+	Matches []gatewayv1.HTTPRouteMatch `json:"matches,omitempty"`
+
+	// Filters modify requests and responses, e.g. headers and redirects.
+	// +optional
+	// +kubebuilder:validation:MaxItems=16
+	Filters []gatewayv1.HTTPRouteFilter `json:"filters,omitempty"`
+
+	// Timeouts are the request and backend request timeouts.
+	// +optional
+	Timeouts *gatewayv1.HTTPRouteTimeouts `json:"timeouts,omitempty"`
+}
+
+// ExposedServiceStatus reports the generated route.
+type ExposedServiceStatus struct {
+	// ObservedGeneration is the generation last reconciled by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// URL is the URL of the first hostname once the route is accepted.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Conditions report whether the route was generated and accepted.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Service",type="string",JSONPath=".spec.serviceName"
+// +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url"
+// +kubebuilder:printcolumn:name="Exposed",type="string",JSONPath=".status.conditions[?(@.type=='Exposed')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ExposedService exposes a Service through a typed HTTPRoute definition, as an
+// alternative to Service annotations.
+type ExposedService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExposedServiceSpec   `json:"spec,omitempty"`
+	Status ExposedServiceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExposedServiceList contains a list of ExposedService.
+type ExposedServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExposedService `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExposedService{}, &ExposedServiceList{})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ExposureClassSpec bundles the settings of an exposure profile. Gateway
+// settings are equivalent to the Service annotations of the same name, which
+// take precedence.
+type ExposureClassSpec struct {
+	// Gateway is the Gateway name routes attach to.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// GatewayNamespace is the namespace of the Gateway.
+	// +optional
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+
+	// SectionName is the Gateway listener routes attach to.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+
+	// HostnameTemplate derives the hostname of Services without a hostname
+	// annotation, replacing the Namespace and controller templates.
+	// +optional
+	HostnameTemplate string `json:"hostnameTemplate,omitempty"`
+
+	// Filters are added to every route, e.g. response headers or an HTTPS
+	// redirect.
+	// +optional
+	// +kubebuilder:validation:MaxItems=15
+	Filters []gatewayv1.HTTPRouteFilter `json:"filters,omitempty"`
+
+	// Timeouts are the request and backend request timeouts of every route.
+	// +optional
+	Timeouts *gatewayv1.HTTPRouteTimeouts `json:"timeouts,omitempty"`
+
+	// AuthFilter references an implementation-specific filter in the Gateway
+	// namespace that authenticates requests. It is added to every route as an
+	// ExtensionRef filter.
+	// +optional
+	AuthFilter *gatewayv1.LocalObjectReference `json:"authFilter,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Gateway",type="string",JSONPath=".spec.gateway"
+// +kubebuilder:printcolumn:name="Section",type="string",JSONPath=".spec.sectionName"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ExposureClass is a reusable exposure profile Services select with the
+// httproute.controller/exposure-class annotation.
+type ExposureClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ExposureClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExposureClassList contains a list of ExposureClass.
+type ExposureClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExposureClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExposureClass{}, &ExposureClassList{})
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExposurePolicySpec defines the CEL rules routes in the selected namespaces must satisfy.
+type ExposurePolicySpec struct {
+	// NamespaceSelector selects the namespaces the policy applies to.
+	// An absent selector applies the policy to all namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// Rules must all evaluate to true for a route to be created.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=64
+	Rules []ExposurePolicyRule `json:"rules"`
+}
+
+// ExposurePolicyRule is a CEL expression evaluated before a route is created.
+type ExposurePolicyRule struct {
+	// Expression is a CEL expression returning true when the route is allowed.
+	// It can use the variables service and namespaceObject, holding the
+	// Service and its Namespace as in the API, and route with the fields
+	// hostnames, gateway, gatewayNamespace, sectionName and port.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=4096
+	Expression string `json:"expression"`
+
+	// Message is reported when the expression denies a route. Defaults to
+	// the expression.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ExposurePolicyStatus reports whether the policy rules compile.
+type ExposurePolicyStatus struct {
+	// ObservedGeneration is the generation last reconciled by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions report whether the policy is valid.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Valid",type="string",JSONPath=".status.conditions[?(@.type=='Valid')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ExposurePolicy denies routes that do not satisfy CEL rules.
+type ExposurePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExposurePolicySpec   `json:"spec,omitempty"`
+	Status ExposurePolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExposurePolicyList contains a list of ExposurePolicy.
+type ExposurePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExposurePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExposurePolicy{}, &ExposurePolicyList{})
+}
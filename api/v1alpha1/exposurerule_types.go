@@ -0,0 +1,131 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ExposureRuleSpec selects Services in the rule's namespace and exposes them
+// with the given settings. Settings are equivalent to the Service annotations
+// of the same name, which take precedence.
+// +kubebuilder:validation:XValidation:rule="has(self.serviceName) != has(self.selector)",message="exactly one of serviceName and selector is required"
+type ExposureRuleSpec struct {
+	// ServiceName selects a single Service by name.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// Selector selects Services by label.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Hostname is the hostname of the route. Without it the hostname is
+	// derived from the Namespace or controller hostname template.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// Gateway is the Gateway name the route attaches to.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// GatewayNamespace is the namespace of the Gateway.
+	// +optional
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+
+	// SectionName is the Gateway listener the route attaches to.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+
+	// Port is the Service port traffic is sent to. Defaults to the first port.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port,omitempty"`
+
+	// SkipReferenceGrant skips creating the ReferenceGrant.
+	// +optional
+	SkipReferenceGrant *bool `json:"skipReferenceGrant,omitempty"`
+
+	// ExposureClass is the ExposureClass providing defaults for the settings above.
+	// +optional
+	ExposureClass string `json:"exposureClass,omitempty"`
+}
+
+// ExposureRuleService reports the route state of a Service matched by the rule.
+type ExposureRuleService struct {
+	// Name is the Service name.
+	Name string `json:"name"`
+
+	// Hostname is the hostname the Service is exposed under.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+
+	// URL is the URL the Service is reachable at once its route is accepted.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Exposed is the status of the Service's Exposed condition.
+	// +optional
+	Exposed metav1.ConditionStatus `json:"exposed,omitempty"`
+
+	// Reason is the reason of the Service's Exposed condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the message of the Service's Exposed condition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ExposureRuleStatus reports the Services matched by the rule.
+type ExposureRuleStatus struct {
+	// ObservedGeneration is the generation last reconciled by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Services lists the matched Services and their route state.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Services []ExposureRuleService `json:"services,omitempty"`
+
+	// Conditions report whether the rule is valid.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Service",type="string",JSONPath=".spec.serviceName"
+// +kubebuilder:printcolumn:name="Hostname",type="string",JSONPath=".spec.hostname"
+// +kubebuilder:printcolumn:name="Valid",type="string",JSONPath=".status.conditions[?(@.type=='Valid')].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ExposureRule exposes Services that cannot carry annotations, e.g. Services
+// rendered by third-party Helm charts.
+type ExposureRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ExposureRuleSpec   `json:"spec,omitempty"`
+	Status ExposureRuleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ExposureRuleList contains a list of ExposureRule.
+type ExposureRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ExposureRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ExposureRule{}, &ExposureRuleList{})
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HostnamePolicySpec defines which hostnames Services in the selected namespaces may claim.
+type HostnamePolicySpec struct {
+	// NamespaceSelector selects the namespaces the policy applies to.
+	// An absent selector applies the policy to all namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// AllowedHostnames lists the hostnames Services may claim, either exact
+	// (login.example.com) or wildcard (*.team-a.example.com).
+	// An empty list allows any hostname that is not denied.
+	// +optional
+	AllowedHostnames []string `json:"allowedHostnames,omitempty"`
+
+	// DeniedHostnames lists hostnames no Service may claim. Denials take
+	// precedence over AllowedHostnames.
+	// +optional
+	DeniedHostnames []string `json:"deniedHostnames,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// HostnamePolicy restricts the hostnames Services may expose through the controller.
+type HostnamePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HostnamePolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HostnamePolicyList contains a list of HostnamePolicy.
+type HostnamePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HostnamePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HostnamePolicy{}, &HostnamePolicyList{})
+}
@@ -11,16 +11,19 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -29,6 +32,7 @@ import (
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
 	"github.com/Piotr1215/httproute-controller/internal/controller"
 	// +kubebuilder:scaffold:imports
 )
@@ -42,6 +46,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(gatewayv1.Install(scheme))
 	utilruntime.Must(gatewayv1beta1.Install(scheme))
+	utilruntime.Must(httproutev1alpha1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
@@ -60,6 +65,11 @@ func main() {
 	// Controller configuration (required flags, no defaults for gateway)
 	var cfg controller.Config
 	cfg.DefaultSectionName = "https" // sane default
+	var gatewayNamespaces string
+	var watchNamespaces, namespaceSelector string
+	var legacyAnnotationPrefixes string
+	var controllerConfig string
+	var publicGateways string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -86,6 +96,43 @@ func main() {
 		"REQUIRED: Default gateway namespace when not specified in Service annotations")
 	flag.StringVar(&cfg.DefaultSectionName, "default-section-name", cfg.DefaultSectionName,
 		"Default gateway listener section name (default: 'https')")
+	flag.StringVar(&gatewayNamespaces, "gateway-namespaces", "",
+		"Comma-separated namespaces whose Gateways are considered for automatic listener selection, "+
+			"in addition to the default gateway namespace")
+	flag.StringVar(&cfg.HostnameTemplate, "hostname-template", "",
+		"Go template deriving the hostname when a Service has no hostname annotation, "+
+			"e.g. '{{.Name}}.{{.Namespace}}.apps.example.com'")
+	flag.StringVar(&cfg.ClusterName, "cluster-name", "",
+		"Cluster name available to hostname templates as {{.ClusterName}}")
+	flag.BoolVar(&cfg.DisableFinalizer, "disable-finalizer", false,
+		"Do not add a finalizer to exposed Services; deleted Services are cleaned up through ownership labels")
+	flag.DurationVar(&cfg.OrphanSweepInterval, "orphan-sweep-interval", controller.DefaultOrphanSweepInterval,
+		"How often generated resources whose Service is gone or no longer exposed are deleted (0 sweeps only at startup)")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma-separated namespaces whose Services are reconciled; restricts the cache so namespace-scoped Roles "+
+			"suffice (default: all namespaces)")
+	flag.StringVar(&namespaceSelector, "namespace-selector", "",
+		"Label selector restricting the namespaces whose Services are reconciled, e.g. 'team in (payments,search)'; "+
+			"only filters reconciles, Services are still watched cluster-wide (see --watch-namespaces)")
+	flag.StringVar(&cfg.ControllerClass, "controller-class", "",
+		"Only reconcile Services whose httproute.controller/class annotation has this value "+
+			"(default: Services without the annotation)")
+	flag.StringVar(&cfg.AnnotationPrefix, "annotation-prefix", controller.AnnotationPrefix,
+		"Prefix of the Service and Namespace annotations and of the finalizer, e.g. 'routing.acme.io'")
+	flag.StringVar(&legacyAnnotationPrefixes, "legacy-annotation-prefixes", "",
+		"Comma-separated prefixes still read after --annotation-prefix; finalizers under them are rewritten")
+	flag.StringVar(&controllerConfig, "controller-config", "default",
+		"Name of the cluster-scoped ControllerConfig overriding the flag configuration at runtime "+
+			"(empty disables it)")
+	flag.BoolVar(&cfg.DefaultClass, "default-class", false,
+		"Also reconcile Services without a httproute.controller/class annotation when --controller-class is set")
+	flag.StringVar(&publicGateways, "public-gateways", "",
+		"Comma-separated Gateways (namespace/name) whose routes are only created once approved")
+	flag.StringVar(&cfg.ApprovalWebhookURL, "approval-webhook-url", "",
+		"URL asked to approve routes to --public-gateways that are not approved by annotation")
+	flag.StringVar(&cfg.ApprovalPolicy, "approval-policy", controller.DefaultApprovalPolicy,
+		"ValidatingAdmissionPolicy restricting who may set the approval annotation; annotation approvals are "+
+			"ignored unless it is installed and bound (empty ignores them all)")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -104,6 +151,51 @@ func main() {
 		os.Exit(1)
 	}
 
+	if gatewayNamespaces != "" {
+		cfg.GatewayNamespaces = strings.Split(gatewayNamespaces, ",")
+	}
+	if cfg.ControllerClass != "" {
+		if errs := controller.ValidateControllerClass(cfg.ControllerClass); len(errs) > 0 {
+			setupLog.Error(nil, "invalid --controller-class", "errors", errs)
+			os.Exit(1)
+		}
+	}
+	if publicGateways != "" {
+		cfg.PublicGateways = strings.Split(publicGateways, ",")
+		for _, gateway := range cfg.PublicGateways {
+			if namespace, name, ok := strings.Cut(gateway, "/"); !ok || namespace == "" || name == "" {
+				setupLog.Error(nil, "invalid --public-gateways, expected namespace/name", "gateway", gateway)
+				os.Exit(1)
+			}
+		}
+	}
+	if legacyAnnotationPrefixes != "" {
+		cfg.LegacyAnnotationPrefixes = strings.Split(legacyAnnotationPrefixes, ",")
+	}
+	for _, prefix := range append([]string{cfg.AnnotationPrefix}, cfg.LegacyAnnotationPrefixes...) {
+		if errs := controller.ValidateAnnotationPrefix(prefix); len(errs) > 0 {
+			setupLog.Error(nil, "invalid annotation prefix", "prefix", prefix, "errors", errs)
+			os.Exit(1)
+		}
+	}
+	if watchNamespaces != "" {
+		cfg.WatchNamespaces = strings.Split(watchNamespaces, ",")
+	}
+	if namespaceSelector != "" {
+		selector, err := labels.Parse(namespaceSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid --namespace-selector")
+			os.Exit(1)
+		}
+		cfg.NamespaceSelector = selector
+	}
+	if cfg.HostnameTemplate != "" {
+		if _, err := controller.ParseHostnameTemplate(cfg.HostnameTemplate); err != nil {
+			setupLog.Error(err, "invalid --hostname-template")
+			os.Exit(1)
+		}
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -193,13 +285,20 @@ func main() {
 		})
 	}
 
+	// Instances of different classes may share a namespace, each needs its own lease
+	leaderElectionID := "httproute-controller.io"
+	if cfg.ControllerClass != "" {
+		leaderElectionID = cfg.ControllerClass + "." + leaderElectionID
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cfg.CacheOptions(),
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "httproute-controller.io",
+		LeaderElectionID:       leaderElectionID,
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -217,21 +316,69 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controller.ServiceReconciler{
+	ctx := ctrl.SetupSignalHandler()
+
+	serviceReconciler := &controller.ServiceReconciler{
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		Config:   cfg,
 		Recorder: mgr.GetEventRecorderFor("httproute-controller"),
-	}).SetupWithManager(mgr); err != nil {
+	}
+	if controllerConfig != "" {
+		installed, err := controller.ControllerConfigInstalled(mgr.GetRESTMapper())
+		if err != nil {
+			setupLog.Error(err, "unable to discover the ControllerConfig API")
+			os.Exit(1)
+		}
+		if !installed {
+			setupLog.Info("ControllerConfig CRD is not installed, using the flag configuration",
+				"controller-config", controllerConfig)
+			controllerConfig = ""
+		}
+	}
+	if controllerConfig != "" {
+		configChanges := make(chan event.GenericEvent)
+		configReconciler := &controller.ControllerConfigReconciler{
+			Client:  mgr.GetClient(),
+			Scheme:  mgr.GetScheme(),
+			Name:    controllerConfig,
+			Base:    cfg,
+			Store:   controller.NewConfigStore(cfg),
+			Changes: configChanges,
+		}
+		// The cache is not running yet, read the ControllerConfig directly
+		if err := configReconciler.Load(ctx, mgr.GetAPIReader()); err != nil {
+			setupLog.Error(err, "unable to load ControllerConfig", "name", controllerConfig)
+			os.Exit(1)
+		}
+		if err := configReconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ControllerConfig")
+			os.Exit(1)
+		}
+		serviceReconciler.Store = configReconciler.Store
+		serviceReconciler.ConfigChanges = configChanges
+	}
+	if err = serviceReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Service")
 		os.Exit(1)
 	}
 
 	setupLog.Info("controller configuration",
-		"annotation-prefix", controller.AnnotationPrefix,
+		"annotation-prefix", cfg.AnnotationPrefix,
+		"legacy-annotation-prefixes", cfg.LegacyAnnotationPrefixes,
 		"default-gateway", cfg.DefaultGateway,
 		"default-gateway-namespace", cfg.DefaultGatewayNamespace,
-		"default-section-name", cfg.DefaultSectionName)
+		"default-section-name", cfg.DefaultSectionName,
+		"gateway-namespaces", cfg.GatewayNamespaces,
+		"hostname-template", cfg.HostnameTemplate,
+		"cluster-name", cfg.ClusterName,
+		"disable-finalizer", cfg.DisableFinalizer,
+		"orphan-sweep-interval", cfg.OrphanSweepInterval,
+		"watch-namespaces", cfg.WatchNamespaces,
+		"namespace-selector", namespaceSelector,
+		"controller-class", cfg.ControllerClass,
+		"default-class", cfg.DefaultClass,
+		"controller-config", controllerConfig)
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
@@ -260,7 +407,7 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
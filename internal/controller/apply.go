@@ -0,0 +1,153 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FieldManager is the server-side apply field manager of generated resources
+const FieldManager = "httproute-controller"
+
+const (
+	// AnnotationSpecHash records the hash of the last applied desired state
+	AnnotationSpecHash = AnnotationPrefix + "/spec-hash"
+	// AnnotationAppliedGeneration records the generation produced by the last apply.
+	// Edits by other actors bump the generation, so a mismatch means drift.
+	AnnotationAppliedGeneration = AnnotationPrefix + "/applied-generation"
+)
+
+// applyResult describes what applyGenerated did
+type applyResult int
+
+const (
+	// applyUnchanged means the resource already matched the desired state
+	applyUnchanged applyResult = iota
+	// applyCreated means the resource did not exist
+	applyCreated
+	// applyUpdated means the desired state changed
+	applyUpdated
+	// applyReverted means the desired state was unchanged but the resource was
+	// modified outside the controller
+	applyReverted
+	// applyRecreated means the resource was deleted outside the controller
+	applyRecreated
+)
+
+// applyGenerated server-side applies desired unless existing (nil when absent)
+// already matches it, and reports what was written. Fields set by other field
+// managers are left alone.
+func (r *ServiceReconciler) applyGenerated(ctx context.Context, desired, existing client.Object) (applyResult, error) {
+	hash, err := desiredHash(desired)
+	if err != nil {
+		return applyUnchanged, err
+	}
+	result := applyCreated
+	if existing != nil {
+		if upToDate(existing, desired, hash) {
+			return applyUnchanged, nil
+		}
+		result = applyUpdated
+		if existing.GetAnnotations()[AnnotationSpecHash] == hash {
+			result = applyReverted
+		}
+	}
+
+	annotations := desired.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationSpecHash] = hash
+	desired.SetAnnotations(annotations)
+	if err := r.apply(ctx, desired); err != nil {
+		return applyUnchanged, err
+	}
+
+	// The generation is only known after the apply
+	original := desired.DeepCopyObject().(client.Object)
+	annotations = desired.GetAnnotations()
+	annotations[AnnotationAppliedGeneration] = strconv.FormatInt(desired.GetGeneration(), 10)
+	desired.SetAnnotations(annotations)
+	return result, r.Patch(ctx, desired, client.MergeFrom(original), client.FieldOwner(FieldManager))
+}
+
+// apply server-side applies obj and stores the result in it. The apply
+// configuration omits status and empty metadata of the typed object, which
+// would otherwise claim fields the controller does not manage.
+func (r *ServiceReconciler) apply(ctx context.Context, obj client.Object) error {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+	u := &unstructured.Unstructured{Object: content}
+	if err := r.Patch(ctx, u, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
+
+// appliedBy reports whether the controller's field manager applied obj, which
+// identifies generated resources even after their labels were removed.
+func appliedBy(obj client.Object) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply {
+			return true
+		}
+	}
+	return false
+}
+
+// upToDate reports whether existing still holds the desired state last applied:
+// same desired hash, no spec edits since, and labels and owner references intact.
+func upToDate(existing, desired client.Object, hash string) bool {
+	annotations := existing.GetAnnotations()
+	if annotations[AnnotationSpecHash] != hash ||
+		annotations[AnnotationAppliedGeneration] != strconv.FormatInt(existing.GetGeneration(), 10) {
+		return false
+	}
+	labels := existing.GetLabels()
+	for k, v := range desired.GetLabels() {
+		if labels[k] != v {
+			return false
+		}
+	}
+	for _, want := range desired.GetOwnerReferences() {
+		found := false
+		for _, ref := range existing.GetOwnerReferences() {
+			if ref.UID == want.UID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// desiredHash returns a short hash of the desired object.
+func desiredHash(desired client.Object) (string, error) {
+	data, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:16], nil
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Server-side apply", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When the desired HTTPRoute is unchanged", func() {
+		It("should not write the HTTPRoute again", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Exposed Service with a reconciled route
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-noop",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "noop.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-noop"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, routeKey, route); err != nil {
+					return ""
+				}
+				return route.Annotations[AnnotationAppliedGeneration]
+			}, timeout, interval).ShouldNot(BeEmpty())
+			resourceVersion := route.ResourceVersion
+
+			// ACT: Reconcile again
+			reconciler := &ServiceReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Config: Config{
+					DefaultGateway:          "test-gateway",
+					DefaultGatewayNamespace: "envoy-gateway-system",
+					DefaultSectionName:      "https",
+				},
+			}
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(svc)})
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: HTTPRoute was not written
+			Expect(k8sClient.Get(ctx, routeKey, route)).Should(Succeed())
+			Expect(route.ResourceVersion).To(Equal(resourceVersion))
+		})
+	})
+
+	Context("When another actor annotates the generated HTTPRoute", func() {
+		It("should keep the foreign fields when updating the route", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Exposed Service with a reconciled route
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-foreign-fields",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "foreign.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-foreign-fields"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, route)
+			}, timeout, interval).Should(Succeed())
+
+			// ARRANGE: Another actor annotates the route
+			original := route.DeepCopy()
+			if route.Annotations == nil {
+				route.Annotations = map[string]string{}
+			}
+			route.Annotations["external-dns.alpha.kubernetes.io/target"] = "lb.example.com"
+			Expect(k8sClient.Patch(ctx, route, client.MergeFrom(original), client.FieldOwner("external-dns"))).
+				Should(Succeed())
+
+			// ACT: Change the hostname
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			svc.Annotations["httproute.controller/hostname"] = "foreign-updated.example.com"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+
+			// ASSERT: Route is updated and keeps the foreign annotation
+			Eventually(func() []gatewayv1.Hostname {
+				if err := k8sClient.Get(ctx, routeKey, route); err != nil {
+					return nil
+				}
+				return route.Spec.Hostnames
+			}, timeout, interval).Should(ConsistOf(gatewayv1.Hostname("foreign-updated.example.com")))
+			Expect(route.Annotations).To(HaveKeyWithValue("external-dns.alpha.kubernetes.io/target", "lb.example.com"))
+		})
+	})
+
+	Context("When a blocked Service is reconciled repeatedly", func() {
+		It("should only emit a Warning event when the reason changes", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service of class events, ignored by the unclassed suite controller
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-blocked-events",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose": "true",
+						"httproute.controller/class":  "events",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &ServiceReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+				Config: Config{
+					DefaultGateway:          "test-gateway",
+					DefaultGatewayNamespace: "envoy-gateway-system",
+					ControllerClass:         "events",
+				},
+			}
+
+			// ACT: Block the Service twice for the same reason, then for another
+			Expect(reconciler.blockExposure(ctx, svc, ReasonHostnameDenied, "denied")).To(Succeed())
+			Expect(reconciler.blockExposure(ctx, svc, ReasonHostnameDenied, "denied")).To(Succeed())
+			Expect(reconciler.blockExposure(ctx, svc, ReasonExposureDenied, "denied by policy")).To(Succeed())
+
+			// ASSERT: One event per distinct reason
+			Expect(recorder.Events).To(HaveLen(2))
+			Expect(<-recorder.Events).To(ContainSubstring(ReasonHostnameDenied))
+			Expect(<-recorder.Events).To(ContainSubstring(ReasonExposureDenied))
+		})
+	})
+})
@@ -0,0 +1,333 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationApprovedHostnames approves routing the listed hostnames
+	// (comma-separated, "*" for a route without hostnames) through a public
+	// Gateway. Changing the hostnames requires a new approval. Unlike other
+	// annotations it is not read under legacy prefixes.
+	AnnotationApprovedHostnames = AnnotationPrefix + "/approved-hostnames"
+	// ConditionPendingApproval is True while a route to a public Gateway waits for approval
+	ConditionPendingApproval = "PendingApproval"
+	// ReasonPendingApproval means the route targets a public Gateway and is not approved yet
+	ReasonPendingApproval = "PendingApproval"
+	// ConditionApproved reports in its message the hostnames, Gateways and
+	// port the approval webhook approved
+	ConditionApproved = "Approved"
+	// ReasonWebhookApproved means the approval webhook approved the route
+	ReasonWebhookApproved = "WebhookApproved"
+)
+
+// DefaultApprovalPolicy is the ValidatingAdmissionPolicy installed from config/approval
+const DefaultApprovalPolicy = "httproute-controller-approval"
+
+//nolint:lll
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingadmissionpolicies,verbs=get;list;watch
+//nolint:lll
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingadmissionpolicybindings,verbs=get;list;watch
+
+// approvalRequeue is how often a pending approval is asked from the webhook again
+const approvalRequeue = time.Minute
+
+// approvalTTL is how long a webhook approval is trusted before the webhook is
+// asked again, so revoked approvals remove the route
+const approvalTTL = 10 * time.Minute
+
+// approvalTimeout bounds a call to the approval webhook
+const approvalTimeout = 10 * time.Second
+
+var approvalClient = &http.Client{Timeout: approvalTimeout}
+
+// webhookApproval is a cached approval webhook decision.
+type webhookApproval struct {
+	message string
+	expires time.Time
+}
+
+// webhookApprovals caches webhook approvals by object UID. They are kept in
+// memory rather than in the status, which tenants may be allowed to write.
+var webhookApprovals = struct {
+	sync.Mutex
+	approvals map[types.UID]webhookApproval
+}{approvals: map[types.UID]webhookApproval{}}
+
+// cachedApproval reports whether the webhook approved message for uid within approvalTTL.
+func cachedApproval(uid types.UID, message string) bool {
+	webhookApprovals.Lock()
+	defer webhookApprovals.Unlock()
+	approval, ok := webhookApprovals.approvals[uid]
+	return ok && approval.message == message && time.Now().Before(approval.expires)
+}
+
+// cacheApproval records or, for an empty message, forgets the webhook approval
+// of uid. Expired approvals, e.g. of deleted objects, are dropped meanwhile.
+func cacheApproval(uid types.UID, message string) {
+	webhookApprovals.Lock()
+	defer webhookApprovals.Unlock()
+	now := time.Now()
+	for cached, approval := range webhookApprovals.approvals {
+		if !now.Before(approval.expires) {
+			delete(webhookApprovals.approvals, cached)
+		}
+	}
+	if message == "" {
+		delete(webhookApprovals.approvals, uid)
+		return
+	}
+	webhookApprovals.approvals[uid] = webhookApproval{message: message, expires: now.Add(approvalTTL)}
+}
+
+// recheckApproval shortens requeue so the webhook is asked again once the
+// cached approval of uid expires.
+func recheckApproval(uid types.UID, requeue time.Duration) time.Duration {
+	webhookApprovals.Lock()
+	defer webhookApprovals.Unlock()
+	approval, ok := webhookApprovals.approvals[uid]
+	if !ok {
+		return requeue
+	}
+	if remaining := max(time.Until(approval.expires), time.Second); requeue == 0 || remaining < requeue {
+		return remaining
+	}
+	return requeue
+}
+
+// approvalRequest is the body POSTed to the approval webhook.
+type approvalRequest struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Hostnames []string `json:"hostnames"`
+	Gateways  []string `json:"gateways"`
+	Port      int32    `json:"port"`
+}
+
+// approvalResponse is the decision returned by the approval webhook.
+type approvalResponse struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// publicTargets returns the targets that are public Gateways.
+func (r *ServiceReconciler) publicTargets(targets []gatewayTarget) []string {
+	var public []string
+	for _, target := range targets {
+		gateway := target.Namespace + "/" + target.Name
+		if slices.Contains(r.config().PublicGateways, gateway) && !slices.Contains(public, gateway) {
+			public = append(public, gateway)
+		}
+	}
+	return public
+}
+
+// checkApproval returns a message describing why the route of obj must wait
+// for approval, or an empty string when it targets no public Gateway or is
+// approved by annotation or by the approval webhook. Annotation approvals
+// count only while the admission policy guards them. A webhook approval is
+// returned as approved, to be reported in the Approved condition, and the
+// webhook is asked again after approvalTTL or when the hostnames, Gateways or
+// port change. Webhook errors are returned, so an approved route stays while
+// the call is retried.
+func (r *ServiceReconciler) checkApproval(
+	ctx context.Context, obj client.Object, kind string,
+	targets []gatewayTarget, hostnames []string, port int32,
+) (pending, approved string, err error) {
+	public := r.publicTargets(targets)
+	// Only the current prefix is read, the admission policy does not guard legacy prefixes
+	approval := obj.GetAnnotations()[r.key(AnnotationApprovedHostnames)]
+	if len(public) == 0 {
+		cacheApproval(obj.GetUID(), "")
+		return "", "", nil
+	}
+	message := fmt.Sprintf("route to public Gateway %s requires approval of %s",
+		strings.Join(public, ", "), r.key(AnnotationApprovedHostnames))
+	if approvedHostnames(approval, hostnames) {
+		guarded, err := r.approvalPolicyInstalled(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		if guarded {
+			cacheApproval(obj.GetUID(), "")
+			return "", "", nil
+		}
+		message = fmt.Sprintf("%s; the annotation is ignored because ValidatingAdmissionPolicy %q is not installed",
+			message, r.config().ApprovalPolicy)
+	}
+
+	url := r.config().ApprovalWebhookURL
+	if url == "" {
+		return message, "", nil
+	}
+	recorded := approvalMessage(hostnames, public, port)
+	if cachedApproval(obj.GetUID(), recorded) {
+		return "", recorded, nil
+	}
+	decision, err := requestApproval(ctx, url, approvalRequest{
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Hostnames: hostnames,
+		Gateways:  public,
+		Port:      port,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("approval webhook failed: %w", err)
+	}
+	if !decision.Approved {
+		cacheApproval(obj.GetUID(), "")
+		return fmt.Sprintf("%s: %s", message, valueOrDefault(decision.Reason, "not approved by webhook")), "", nil
+	}
+	cacheApproval(obj.GetUID(), recorded)
+	return "", recorded, nil
+}
+
+// approvalPolicyInstalled reports whether the ApprovalPolicy exists, checks the
+// approval annotation under the current prefix and is bound to deny
+// violating requests, so only approvers can have set the annotation.
+func (r *ServiceReconciler) approvalPolicyInstalled(ctx context.Context) (bool, error) {
+	name := r.config().ApprovalPolicy
+	if name == "" {
+		return false, nil
+	}
+	policy := &admissionregistrationv1.ValidatingAdmissionPolicy{}
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, policy); err != nil {
+		// Clusters before Kubernetes 1.30 do not serve the API
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if policy.Spec.FailurePolicy != nil && *policy.Spec.FailurePolicy != admissionregistrationv1.Fail {
+		return false, nil
+	}
+	key := r.key(AnnotationApprovedHostnames)
+	if !slices.ContainsFunc(policy.Spec.Variables, func(v admissionregistrationv1.Variable) bool {
+		return strings.Contains(v.Expression, "'"+key+"'")
+	}) {
+		return false, nil
+	}
+	bindings := &admissionregistrationv1.ValidatingAdmissionPolicyBindingList{}
+	if err := r.List(ctx, bindings); err != nil {
+		return false, err
+	}
+	for _, binding := range bindings.Items {
+		if binding.Spec.PolicyName == name && binding.Spec.MatchResources == nil &&
+			slices.Contains(binding.Spec.ValidationActions, admissionregistrationv1.Deny) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// approvalMessage describes a webhook approval of hostnames on the public
+// Gateways and port, independent of the order of hostnames.
+func approvalMessage(hostnames, public []string, port int32) string {
+	sorted := slices.Sorted(slices.Values(hostnames))
+	return fmt.Sprintf("approved by webhook for hostnames %s on %s port %d",
+		strings.Join(sorted, ", "), strings.Join(public, ", "), port)
+}
+
+// approvedHostnames reports whether the approval annotation value covers all hostnames.
+func approvedHostnames(approval string, hostnames []string) bool {
+	if approval == "" {
+		return false
+	}
+	approved := strings.Split(approval, ",")
+	for i := range approved {
+		approved[i] = strings.TrimSpace(approved[i])
+	}
+	if len(hostnames) == 0 {
+		return slices.Contains(approved, "*")
+	}
+	for _, hostname := range hostnames {
+		if !slices.Contains(approved, hostname) {
+			return false
+		}
+	}
+	return true
+}
+
+// requestApproval asks the approval webhook at url for a decision on req.
+func requestApproval(ctx context.Context, url string, req approvalRequest) (approvalResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return approvalResponse{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return approvalResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := approvalClient.Do(httpReq)
+	if err != nil {
+		return approvalResponse{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return approvalResponse{}, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var decision approvalResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return approvalResponse{}, fmt.Errorf("invalid response: %w", err)
+	}
+	return decision, nil
+}
+
+// awaitApproval removes the route of svc and marks it pending approval. The
+// webhook decision is polled, annotation approvals trigger a reconcile.
+// blockExposure drops a previous webhook approval.
+func (r *ServiceReconciler) awaitApproval(
+	ctx context.Context, svc *corev1.Service, message string,
+) (ctrl.Result, error) {
+	if err := r.blockExposure(ctx, svc, ReasonPendingApproval, message); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setCondition(ctx, svc, ConditionPendingApproval, metav1.ConditionTrue,
+		ReasonPendingApproval, message); err != nil {
+		return ctrl.Result{}, err
+	}
+	return r.approvalResult(), nil
+}
+
+// recordApproval sets the Approved condition of svc to a webhook approval, or
+// removes it when the route needed none.
+func (r *ServiceReconciler) recordApproval(ctx context.Context, svc *corev1.Service, approved string) error {
+	if approved == "" {
+		return r.removeCondition(ctx, svc, ConditionApproved)
+	}
+	return r.setCondition(ctx, svc, ConditionApproved, metav1.ConditionTrue, ReasonWebhookApproved, approved)
+}
+
+// approvalResult requeues pending approvals while the webhook can still approve them.
+func (r *ServiceReconciler) approvalResult() ctrl.Result {
+	if r.config().ApprovalWebhookURL == "" {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: approvalRequeue}
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Approval", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	approvalReconciler := func(webhookURL string) *ServiceReconciler {
+		return &ServiceReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: Config{
+				DefaultGateway:          "test-gateway",
+				DefaultGatewayNamespace: "envoy-gateway-system",
+				DefaultSectionName:      "https",
+				ControllerClass:         "approval",
+				PublicGateways:          []string{"envoy-gateway-system/test-gateway"},
+				ApprovalWebhookURL:      webhookURL,
+				ApprovalPolicy:          "test-approval",
+			},
+		}
+	}
+	// installApprovalPolicy creates a bound policy checking the approval
+	// annotation key and returns a function deleting it
+	installApprovalPolicy := func(ctx context.Context, name, key string) func() {
+		policy := &admissionregistrationv1.ValidatingAdmissionPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+				MatchConstraints: &admissionregistrationv1.MatchResources{
+					ResourceRules: []admissionregistrationv1.NamedRuleWithOperations{{
+						RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+							Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+							Rule: admissionregistrationv1.Rule{
+								APIGroups:   []string{""},
+								APIVersions: []string{"v1"},
+								Resources:   []string{"services"},
+							},
+						},
+					}},
+				},
+				Variables: []admissionregistrationv1.Variable{{
+					Name:       "approval",
+					Expression: "object.metadata.?annotations['" + key + "'].orValue('')",
+				}},
+				Validations: []admissionregistrationv1.Validation{{
+					Expression: "variables.approval == '' || authorizer.group('httproute.controller')" +
+						".resource('exposures').check('approve').allowed()",
+				}},
+			},
+		}
+		binding := &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+				PolicyName:        name,
+				ValidationActions: []admissionregistrationv1.ValidationAction{admissionregistrationv1.Deny},
+			},
+		}
+		Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+		Expect(k8sClient.Create(ctx, binding)).Should(Succeed())
+		return func() {
+			_ = k8sClient.Delete(ctx, binding)
+			_ = k8sClient.Delete(ctx, policy)
+		}
+	}
+	public := []gatewayTarget{{Name: "test-gateway", Namespace: "envoy-gateway-system"}}
+
+	Context("When matching approvals to hostnames", func() {
+		It("should require every hostname to be approved", func() {
+			Expect(approvedHostnames("", []string{"a.example.com"})).To(BeFalse())
+			Expect(approvedHostnames("a.example.com", []string{"a.example.com"})).To(BeTrue())
+			Expect(approvedHostnames("a.example.com, b.example.com", []string{"b.example.com", "a.example.com"})).
+				To(BeTrue())
+			Expect(approvedHostnames("a.example.com", []string{"a.example.com", "b.example.com"})).To(BeFalse())
+			Expect(approvedHostnames("a.example.com", nil)).To(BeFalse())
+			Expect(approvedHostnames("*", nil)).To(BeTrue())
+		})
+	})
+
+	Context("When the annotation prefix was renamed", func() {
+		It("should ignore approvals under legacy prefixes", func() {
+			ctx := context.Background()
+			r := approvalReconciler("")
+			r.Config.AnnotationPrefix = "expose.example.com"
+			r.Config.ApprovalPolicy = "test-approval-prefix"
+			defer installApprovalPolicy(ctx, "test-approval-prefix", "expose.example.com/approved-hostnames")()
+			r.Config.LegacyAnnotationPrefixes = []string{"httproute.controller"}
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				Annotations: map[string]string{"httproute.controller/approved-hostnames": "web.example.com"},
+			}}
+
+			pending, _, err := r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(ContainSubstring("expose.example.com/approved-hostnames"))
+
+			svc.Annotations["expose.example.com/approved-hostnames"] = "web.example.com"
+			pending, _, err = r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+	})
+
+	Context("When the admission policy does not guard the annotation", func() {
+		It("should ignore annotation approvals", func() {
+			ctx := context.Background()
+			r := approvalReconciler("")
+			r.Config.ApprovalPolicy = "test-approval-unguarded"
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				Annotations: map[string]string{"httproute.controller/approved-hostnames": "web.example.com"},
+			}}
+
+			// ASSERT: Without the policy the annotation is ignored
+			pending, _, err := r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(ContainSubstring(`ValidatingAdmissionPolicy "test-approval-unguarded" is not installed`))
+
+			// ASSERT: A policy checking another prefix does not count
+			cleanup := installApprovalPolicy(ctx, "test-approval-unguarded", "expose.example.com/approved-hostnames")
+			pending, _, err = r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).NotTo(BeEmpty())
+			cleanup()
+
+			// ACT: Install the policy for the current prefix
+			defer installApprovalPolicy(ctx, "test-approval-unguarded", "httproute.controller/approved-hostnames")()
+
+			// ASSERT: The annotation approves the route
+			pending, _, err = r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+	})
+
+	Context("When asking the approval webhook", func() {
+		It("should only approve routes the webhook approves", func() {
+			ctx := context.Background()
+			var received approvalRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(json.NewDecoder(req.Body).Decode(&received)).To(Succeed())
+				_ = json.NewEncoder(w).Encode(approvalResponse{
+					Approved: received.Hostnames[0] == "approved.example.com",
+					Reason:   "change ticket missing",
+				})
+			}))
+			defer server.Close()
+			r := approvalReconciler(server.URL)
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+
+			pending, approved, err := r.checkApproval(ctx, svc, "Service", public,
+				[]string{"approved.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+			Expect(approved).To(ContainSubstring("approved.example.com"))
+			Expect(received.Gateways).To(ConsistOf("envoy-gateway-system/test-gateway"))
+			Expect(received.Kind).To(Equal("Service"))
+
+			pending, approved, err = r.checkApproval(ctx, svc, "Service", public,
+				[]string{"other.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(ContainSubstring("change ticket missing"))
+			Expect(approved).To(BeEmpty())
+
+			private := []gatewayTarget{{Name: "internal", Namespace: "envoy-gateway-system"}}
+			pending, _, err = r.checkApproval(ctx, svc, "Service", private, []string{"other.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pending).To(BeEmpty())
+		})
+
+		It("should ask again when the cached approval expires", func() {
+			ctx := context.Background()
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls++
+				_ = json.NewEncoder(w).Encode(approvalResponse{Approved: calls == 1})
+			}))
+			defer server.Close()
+			r := approvalReconciler(server.URL)
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", UID: "approval-ttl"}}
+
+			// ASSERT: The approval is cached
+			_, approved, err := r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approved).To(Equal(
+				approvalMessage([]string{"web.example.com"}, []string{"envoy-gateway-system/test-gateway"}, 80)))
+			_, _, err = r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(1))
+			Expect(recheckApproval(svc.UID, time.Hour)).To(BeNumerically("<=", approvalTTL))
+
+			// ACT: Let the cached approval expire
+			webhookApprovals.Lock()
+			approval := webhookApprovals.approvals[svc.UID]
+			approval.expires = time.Now()
+			webhookApprovals.approvals[svc.UID] = approval
+			webhookApprovals.Unlock()
+
+			// ASSERT: The webhook is asked again and the revoked approval no longer applies
+			pending, approved, err := r.checkApproval(ctx, svc, "Service", public, []string{"web.example.com"}, 80)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(2))
+			Expect(pending).NotTo(BeEmpty())
+			Expect(approved).To(BeEmpty())
+		})
+
+		It("should return webhook failures as errors", func() {
+			ctx := context.Background()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer server.Close()
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+
+			pending, _, err := approvalReconciler(server.URL).checkApproval(ctx, svc, "Service", public,
+				[]string{"web.example.com"}, 80)
+			Expect(err).To(MatchError(ContainSubstring("approval webhook failed")))
+			Expect(pending).To(BeEmpty())
+		})
+	})
+
+	Context("When a Service targets a public Gateway", func() {
+		It("should wait for the approval annotation before creating the route", func() {
+			ctx := context.Background()
+			reconciler := approvalReconciler("")
+			defer installApprovalPolicy(ctx, "test-approval", "httproute.controller/approved-hostnames")()
+
+			// ARRANGE: Service of class approval, ignored by the unclassed suite controller
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-approval",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "approval.example.com",
+						"httproute.controller/class":    "approval",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{
+						Name:       "http",
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+			request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(svc)}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-approval"),
+				Namespace: "envoy-gateway-system",
+			}
+
+			// ACT: Reconcile without approval
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: No route and PendingApproval is True
+			Expect(k8sClient.Get(ctx, request.NamespacedName, svc)).Should(Succeed())
+			Expect(meta.IsStatusConditionTrue(svc.Status.Conditions, ConditionPendingApproval)).To(BeTrue())
+			exposed := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed)
+			Expect(exposed).NotTo(BeNil())
+			Expect(exposed.Reason).To(Equal(ReasonPendingApproval))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{}))).To(BeTrue())
+
+			// ACT: Approve the hostname and reconcile
+			svc.Annotations["httproute.controller/approved-hostnames"] = "approval.example.com"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+			Eventually(func() error {
+				_, err := reconciler.Reconcile(ctx, request)
+				return err
+			}, timeout, interval).Should(Succeed())
+
+			// ASSERT: Route exists and PendingApproval is gone
+			Expect(k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{})).Should(Succeed())
+			Expect(k8sClient.Get(ctx, request.NamespacedName, svc)).Should(Succeed())
+			Expect(meta.FindStatusCondition(svc.Status.Conditions, ConditionPendingApproval)).To(BeNil())
+
+			// ACT: Change the hostname
+			svc.Annotations["httproute.controller/hostname"] = "approval2.example.com"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+			Eventually(func() error {
+				_, err := reconciler.Reconcile(ctx, request)
+				return err
+			}, timeout, interval).Should(Succeed())
+
+			// ASSERT: The previous approval no longer applies
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{}))).To(BeTrue())
+		})
+	})
+})
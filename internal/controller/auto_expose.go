@@ -0,0 +1,71 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// AnnotationAutoExpose on a Namespace set to "true" exposes every Service in
+	// it that does not opt out with expose: "false"
+	AnnotationAutoExpose = AnnotationPrefix + "/auto-expose"
+	// AnnotationAutoExposeSelector on a Namespace limits auto-expose to Services
+	// matching a label selector, e.g. app.kubernetes.io/component=web
+	AnnotationAutoExposeSelector = AnnotationPrefix + "/auto-expose-selector"
+	// AnnotationAutoExposed records that a Service is exposed through its
+	// Namespace, so watches and indexes can tell without reading the Namespace
+	AnnotationAutoExposed = AnnotationPrefix + "/auto-exposed"
+)
+
+// resolveExposure reports whether svc should have a route, through its expose
+// annotation, an ExposureRule or Namespace auto-expose, and records the
+// matching rule and auto-expose on the Service.
+func (r *ServiceReconciler) resolveExposure(ctx context.Context, svc *corev1.Service) (bool, error) {
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return false, err
+	}
+	auto := false
+	if expose := r.setting(s, AnnotationExpose); expose != "true" && expose != "false" {
+		if auto, err = r.autoExposes(s.ns, svc); err != nil {
+			return false, err
+		}
+	}
+
+	values := map[string]string{AnnotationAutoExposed: "", AnnotationExposureRule: ""}
+	if auto {
+		values[AnnotationAutoExposed] = "true"
+	}
+	if s.rule != nil {
+		values[AnnotationExposureRule] = s.rule.Name
+	}
+	if err := r.patchAnnotations(ctx, svc, values); err != nil {
+		return false, err
+	}
+	return r.isExposed(svc), nil
+}
+
+// autoExposes reports whether ns exposes svc through auto-expose.
+func (r *ServiceReconciler) autoExposes(ns *corev1.Namespace, svc *corev1.Service) (bool, error) {
+	if r.annotation(ns, AnnotationAutoExpose) != "true" {
+		return false, nil
+	}
+	text := r.annotation(ns, AnnotationAutoExposeSelector)
+	if text == "" {
+		return true, nil
+	}
+	selector, err := labels.Parse(text)
+	if err != nil {
+		return false, fmt.Errorf("namespace %s has an invalid auto-expose selector: %w", ns.Name, err)
+	}
+	return selector.Matches(labels.Set(svc.Labels)), nil
+}
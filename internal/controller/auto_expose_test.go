@@ -0,0 +1,121 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Namespace auto-expose", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	newService := func(name string, labels, annotations map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "preview-auto",
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					},
+				},
+			},
+		}
+	}
+
+	Context("When a Namespace auto-exposes Services matching a selector", func() {
+		It("should expose matching Services unless they opt out", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Auto-expose Namespace with a selector and hostname suffix
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "preview-auto",
+					Annotations: map[string]string{
+						"httproute.controller/auto-expose":          "true",
+						"httproute.controller/auto-expose-selector": "tier=web",
+						"httproute.controller/hostname-suffix":      "preview.example.com",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+			web := newService("test-svc-auto-web", map[string]string{"tier": "web"}, nil)
+			db := newService("test-svc-auto-db", map[string]string{"tier": "db"}, nil)
+			optOut := newService("test-svc-auto-optout", map[string]string{"tier": "web"},
+				map[string]string{"httproute.controller/expose": "false"})
+
+			// ACT: Create the Services without expose annotations
+			for _, svc := range []*corev1.Service{web, db, optOut} {
+				Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+				defer func(svc *corev1.Service) { _ = k8sClient.Delete(ctx, svc) }(svc)
+			}
+
+			// ASSERT: Matching Service gets a route with the derived hostname
+			route := &gatewayv1.HTTPRoute{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      routeName("preview-auto", "test-svc-auto-web"),
+					Namespace: "envoy-gateway-system",
+				}, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("test-svc-auto-web.preview.example.com")))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(web), web)).Should(Succeed())
+			Expect(web.Annotations).To(HaveKeyWithValue(AnnotationAutoExposed, "true"))
+
+			// ASSERT: Non-matching and opted-out Services get no route
+			Consistently(func() bool {
+				for _, name := range []string{"test-svc-auto-db", "test-svc-auto-optout"} {
+					err := k8sClient.Get(ctx, types.NamespacedName{
+						Name:      routeName("preview-auto", name),
+						Namespace: "envoy-gateway-system",
+					}, &gatewayv1.HTTPRoute{})
+					if !errors.IsNotFound(err) {
+						return false
+					}
+				}
+				return true
+			}, time.Second, interval).Should(BeTrue())
+
+			// ACT: Turn auto-expose off
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ns), ns)).Should(Succeed())
+			ns.Annotations["httproute.controller/auto-expose"] = "false"
+			Expect(k8sClient.Update(ctx, ns)).Should(Succeed())
+
+			// ASSERT: Route is removed and the Service no longer recorded as auto-exposed
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(route), &gatewayv1.HTTPRoute{})
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+			Eventually(func() map[string]string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(web), web); err != nil {
+					return nil
+				}
+				return web.Annotations
+			}, timeout, interval).ShouldNot(HaveKey(AnnotationAutoExposed))
+		})
+	})
+})
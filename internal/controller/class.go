@@ -0,0 +1,88 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// AnnotationClass selects the controller instance that exposes a Service
+	AnnotationClass = AnnotationPrefix + "/class"
+	// LabelClass records the controller class on generated resources
+	LabelClass = AnnotationPrefix + "/class"
+)
+
+// MaxControllerClassLength keeps the class finalizer name, e.g.
+// httproute-finalizer-<class>, within the 63 characters of a qualified name
+const MaxControllerClassLength = validation.DNS1123LabelMaxLength - len("httproute-finalizer-")
+
+// ValidateControllerClass checks that class can be used in label values,
+// lease names and the class finalizer.
+func ValidateControllerClass(class string) []string {
+	errs := validation.IsDNS1123Label(class)
+	if len(class) > MaxControllerClassLength {
+		errs = append(errs, fmt.Sprintf("must be no more than %d characters", MaxControllerClassLength))
+	}
+	return errs
+}
+
+// matchesClass reports whether svc belongs to the controller class. Services
+// without a class belong to an unclassed controller and to the default class.
+func (r *ServiceReconciler) matchesClass(svc *corev1.Service) bool {
+	class := r.annotation(svc, AnnotationClass)
+	if class == "" {
+		return r.config().ControllerClass == "" || r.config().DefaultClass
+	}
+	return class == r.config().ControllerClass
+}
+
+// finalizer returns the finalizer of the controller class under the configured
+// prefix, so instances of different classes never remove each other's finalizers.
+func (r *ServiceReconciler) finalizer() string {
+	return r.key(r.classFinalizer())
+}
+
+// classFinalizer returns the finalizer of the controller class under AnnotationPrefix.
+func (r *ServiceReconciler) classFinalizer() string {
+	if r.config().ControllerClass == "" {
+		return FinalizerHTTPRoute
+	}
+	return FinalizerHTTPRoute + "-" + r.config().ControllerClass
+}
+
+// generatedLabels returns the labels of resources generated for svc,
+// including the controller class when one is set.
+func (r *ServiceReconciler) generatedLabels(svc *corev1.Service) map[string]string {
+	generated := serviceLabels(svc)
+	if r.config().ControllerClass != "" {
+		generated[LabelClass] = r.config().ControllerClass
+	}
+	return generated
+}
+
+// classRequirement selects resources generated by the controller class; an
+// unclassed controller selects resources without a class label.
+func (r *ServiceReconciler) classRequirement() labels.Requirement {
+	if r.config().ControllerClass == "" {
+		req, _ := labels.NewRequirement(LabelClass, selection.DoesNotExist, nil)
+		return *req
+	}
+	req, _ := labels.NewRequirement(LabelClass, selection.Equals, []string{r.config().ControllerClass})
+	return *req
+}
+
+// ownsGenerated reports whether a generated resource belongs to the controller class.
+func (r *ServiceReconciler) ownsGenerated(obj client.Object) bool {
+	return obj.GetLabels()[LabelClass] == r.config().ControllerClass
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Controller class", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	classReconciler := func(class string, defaultClass bool) *ServiceReconciler {
+		return &ServiceReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: Config{
+				DefaultGateway:          "test-gateway",
+				DefaultGatewayNamespace: "envoy-gateway-system",
+				DefaultSectionName:      "https",
+				ControllerClass:         class,
+				DefaultClass:            defaultClass,
+			},
+		}
+	}
+
+	Context("When matching Services to a class", func() {
+		It("should send unclassed Services to the unclassed or default instance", func() {
+			unclassed := &corev1.Service{}
+			blue := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{"httproute.controller/class": "blue"},
+			}}
+
+			Expect(classReconciler("", false).matchesClass(unclassed)).To(BeTrue())
+			Expect(classReconciler("", false).matchesClass(blue)).To(BeFalse())
+			Expect(classReconciler("blue", false).matchesClass(unclassed)).To(BeFalse())
+			Expect(classReconciler("blue", false).matchesClass(blue)).To(BeTrue())
+			Expect(classReconciler("green", true).matchesClass(unclassed)).To(BeTrue())
+			Expect(classReconciler("green", true).matchesClass(blue)).To(BeFalse())
+		})
+
+		It("should use a finalizer per class", func() {
+			Expect(classReconciler("", false).finalizer()).To(Equal(FinalizerHTTPRoute))
+			Expect(classReconciler("blue", false).finalizer()).To(Equal(FinalizerHTTPRoute + "-blue"))
+		})
+
+		It("should reject classes whose finalizer would exceed a qualified name", func() {
+			longest := strings.Repeat("c", MaxControllerClassLength)
+			Expect(ValidateControllerClass(longest)).To(BeEmpty())
+			Expect(validation.IsQualifiedName(classReconciler(longest, false).finalizer())).To(BeEmpty())
+			Expect(ValidateControllerClass(longest + "c")).NotTo(BeEmpty())
+			Expect(ValidateControllerClass("Blue")).NotTo(BeEmpty())
+		})
+	})
+
+	Context("When a Service moves to another class", func() {
+		It("should be released by the previous instance", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service of class blue, ignored by the unclassed suite controller
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-class",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "class.example.com",
+						"httproute.controller/class":    "blue",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-class"),
+				Namespace: "envoy-gateway-system",
+			}
+			Consistently(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{}))
+			}, time.Second, interval).Should(BeTrue())
+
+			// ACT: Reconcile with the blue instance
+			blue := classReconciler("blue", false)
+			request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(svc)}
+			_, err := blue.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: Route carries the class and the Service the class finalizer
+			route := &gatewayv1.HTTPRoute{}
+			Expect(k8sClient.Get(ctx, routeKey, route)).Should(Succeed())
+			Expect(route.Labels).To(HaveKeyWithValue(LabelClass, "blue"))
+			Expect(k8sClient.Get(ctx, request.NamespacedName, svc)).Should(Succeed())
+			Expect(svc.Finalizers).To(ConsistOf(FinalizerHTTPRoute + "-blue"))
+
+			// ACT: Move the Service to class green and reconcile with the blue instance
+			svc.Annotations["httproute.controller/class"] = "green"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+			Eventually(func() string {
+				current := &corev1.Service{}
+				if err := k8sClient.Get(ctx, request.NamespacedName, current); err != nil {
+					return ""
+				}
+				return current.Annotations["httproute.controller/class"]
+			}, timeout, interval).Should(Equal("green"))
+			_, err = blue.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: Route and finalizer are removed
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, routeKey, route))).To(BeTrue())
+			Expect(k8sClient.Get(ctx, request.NamespacedName, svc)).Should(Succeed())
+			Expect(svc.Finalizers).To(BeEmpty())
+		})
+	})
+})
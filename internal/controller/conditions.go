@@ -0,0 +1,81 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Condition types and reasons written to Service.Status.Conditions
+const (
+	// ConditionExposed reports whether the controller generated a route for the Service
+	ConditionExposed = "Exposed"
+	// ConditionGatewayResolved reports whether the target Gateway and listener exist
+	ConditionGatewayResolved = "GatewayResolved"
+	// ConditionHTTPRouteAccepted mirrors the Accepted condition of the generated route
+	ConditionHTTPRouteAccepted = "HTTPRouteAccepted"
+	// ConditionHTTPRouteResolvedRefs mirrors the ResolvedRefs condition of the generated route
+	ConditionHTTPRouteResolvedRefs = "HTTPRouteResolvedRefs"
+
+	ReasonReconciled       = "Reconciled"
+	ReasonHostnameConflict = "HostnameConflict"
+	ReasonHostnameDenied   = "HostnameDenied"
+	ReasonInvalidHostname  = "InvalidHostname"
+	ReasonResolved         = "Resolved"
+	ReasonGatewayNotFound  = "GatewayNotFound"
+	ReasonListenerNotFound = "ListenerNotFound"
+	ReasonPending          = "Pending"
+)
+
+// setCondition sets a condition on the Service status, patching only when it changed.
+func (r *ServiceReconciler) setCondition(
+	ctx context.Context, svc *corev1.Service, conditionType string,
+	status metav1.ConditionStatus, reason, message string,
+) error {
+	original := svc.DeepCopy()
+	changed := meta.SetStatusCondition(&svc.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: svc.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if !changed {
+		return nil
+	}
+	return r.Status().Patch(ctx, svc, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// removeCondition removes conditions from the Service status if present.
+func (r *ServiceReconciler) removeCondition(ctx context.Context, svc *corev1.Service, conditionTypes ...string) error {
+	original := svc.DeepCopy()
+	changed := false
+	for _, conditionType := range conditionTypes {
+		if meta.RemoveStatusCondition(&svc.Status.Conditions, conditionType) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return r.Status().Patch(ctx, svc, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// conditionChanges reports whether setting the condition would change its
+// status, reason or message. Events are only emitted on such changes, not on
+// every reconcile of a blocked resource.
+func conditionChanges(
+	conditions []metav1.Condition, conditionType string, status metav1.ConditionStatus, reason, message string,
+) bool {
+	cond := meta.FindStatusCondition(conditions, conditionType)
+	return cond == nil || cond.Status != status || cond.Reason != reason || cond.Message != message
+}
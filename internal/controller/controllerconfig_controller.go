@@ -0,0 +1,276 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+const (
+	// ConditionValid reports whether a ControllerConfig is valid and applied
+	ConditionValid = "Valid"
+	// ReasonApplied means the ControllerConfig is in effect
+	ReasonApplied = "Applied"
+	// ReasonInvalidConfig means the ControllerConfig was rejected and the previous configuration stays in effect
+	ReasonInvalidConfig = "InvalidConfig"
+)
+
+// ConfigStore holds the configuration in effect. It is safe for concurrent use.
+type ConfigStore struct {
+	mu  sync.RWMutex
+	cfg Config
+}
+
+// NewConfigStore returns a store holding cfg.
+func NewConfigStore(cfg Config) *ConfigStore {
+	return &ConfigStore{cfg: cfg}
+}
+
+// Get returns the configuration in effect.
+func (s *ConfigStore) Get() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// set replaces the configuration and reports whether it changed.
+func (s *ConfigStore) set(cfg Config) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if reflect.DeepEqual(s.cfg, cfg) {
+		return false
+	}
+	s.cfg = cfg
+	return true
+}
+
+// ControllerConfigReconciler applies the ControllerConfig named Name on top of
+// the flag configuration and reports the result in its status.
+type ControllerConfigReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// Name is the ControllerConfig to read
+	Name string
+	// Base is the configuration given by flags
+	Base  Config
+	Store *ConfigStore
+	// Changes receives an event whenever the configuration in effect changes,
+	// so the Service controller reconciles the exposed Services again
+	Changes chan<- event.GenericEvent
+}
+
+// +kubebuilder:rbac:groups=httproute.controller,resources=controllerconfigs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=httproute.controller,resources=controllerconfigs/status,verbs=get;update;patch
+
+func (r *ControllerConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cc := &httproutev1alpha1.ControllerConfig{}
+	if err := r.Get(ctx, req.NamespacedName, cc); err != nil {
+		if errors.IsNotFound(err) {
+			// Deleted - fall back to the flags
+			return ctrl.Result{}, r.apply(ctx, r.Base, req.Name)
+		}
+		return ctrl.Result{}, err
+	}
+
+	original := cc.DeepCopy()
+	cfg := mergeControllerConfig(r.Base, cc.Spec)
+	if err := r.validate(cfg); err != nil {
+		log.FromContext(ctx).Error(err, "invalid ControllerConfig, keeping the previous configuration", "name", cc.Name)
+		meta.SetStatusCondition(&cc.Status.Conditions, metav1.Condition{
+			Type:               ConditionValid,
+			Status:             metav1.ConditionFalse,
+			Reason:             ReasonInvalidConfig,
+			Message:            err.Error(),
+			ObservedGeneration: cc.Generation,
+		})
+	} else {
+		if err := r.apply(ctx, cfg, cc.Name); err != nil {
+			return ctrl.Result{}, err
+		}
+		meta.SetStatusCondition(&cc.Status.Conditions, metav1.Condition{
+			Type:               ConditionValid,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonApplied,
+			Message:            "Configuration applied",
+			ObservedGeneration: cc.Generation,
+		})
+	}
+	cc.Status.ObservedGeneration = cc.Generation
+	active := activeSpec(r.Store.Get())
+	cc.Status.Active = &active
+
+	if equality.Semantic.DeepEqual(original.Status, cc.Status) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, r.Status().Patch(ctx, cc, client.MergeFrom(original))
+}
+
+// Load applies the ControllerConfig before the manager starts, so Services are
+// never reconciled with the flag configuration first. reader must not depend
+// on the cache. An invalid ControllerConfig is reported once reconciled.
+func (r *ControllerConfigReconciler) Load(ctx context.Context, reader client.Reader) error {
+	cc := &httproutev1alpha1.ControllerConfig{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: r.Name}, cc); err != nil {
+		// The CRD may not be installed yet, e.g. while upgrading
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	cfg := mergeControllerConfig(r.Base, cc.Spec)
+	if err := r.validate(cfg); err != nil {
+		log.FromContext(ctx).Error(err, "invalid ControllerConfig, using the flag configuration", "name", cc.Name)
+		return nil
+	}
+	r.Store.set(cfg)
+	return nil
+}
+
+// ControllerConfigInstalled reports whether the API server serves the
+// ControllerConfig kind. Without the CRD the controller runs on the flags,
+// since watching a missing kind would keep the manager from starting.
+func ControllerConfigInstalled(mapper meta.RESTMapper) (bool, error) {
+	_, err := mapper.RESTMapping(httproutev1alpha1.GroupVersion.WithKind("ControllerConfig").GroupKind(),
+		httproutev1alpha1.GroupVersion.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// apply stores cfg and notifies the Service controller when it changed.
+func (r *ControllerConfigReconciler) apply(ctx context.Context, cfg Config, name string) error {
+	if !r.Store.set(cfg) || r.Changes == nil {
+		return nil
+	}
+	log.FromContext(ctx).Info("configuration changed, reconciling exposed Services", "name", name)
+	select {
+	case r.Changes <- event.GenericEvent{Object: &httproutev1alpha1.ControllerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// broadcastConfigChanges forwards every event received from changes to each of
+// outs until ctx is done, so several controllers react to the same change.
+func broadcastConfigChanges(ctx context.Context, changes <-chan event.GenericEvent, outs ...chan<- event.GenericEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-changes:
+			if !ok {
+				return
+			}
+			for _, out := range outs {
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// validate checks a merged configuration. With WatchNamespaces the cache only
+// covers the gateway namespaces given by flags, so the config cannot add others.
+func (r *ControllerConfigReconciler) validate(cfg Config) error {
+	if cfg.DefaultGateway == "" || cfg.DefaultGatewayNamespace == "" {
+		return fmt.Errorf("defaultGateway and defaultGatewayNamespace are required")
+	}
+	namespaces := append([]string{cfg.DefaultGatewayNamespace}, cfg.GatewayNamespaces...)
+	cached := append([]string{r.Base.DefaultGatewayNamespace}, r.Base.GatewayNamespaces...)
+	for _, ns := range namespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid gateway namespace %q: %v", ns, errs)
+		}
+		if len(r.Base.WatchNamespaces) > 0 && !slices.Contains(cached, ns) {
+			return fmt.Errorf("gateway namespace %s is not cached; add it to --gateway-namespaces", ns)
+		}
+	}
+	if cfg.HostnameTemplate != "" {
+		if _, err := ParseHostnameTemplate(cfg.HostnameTemplate); err != nil {
+			return fmt.Errorf("invalid hostnameTemplate: %w", err)
+		}
+	}
+	return nil
+}
+
+// mergeControllerConfig overrides base with the fields set in spec.
+func mergeControllerConfig(base Config, spec httproutev1alpha1.ControllerConfigSpec) Config {
+	cfg := base
+	cfg.DefaultGateway = valueOrDefault(spec.DefaultGateway, base.DefaultGateway)
+	cfg.DefaultGatewayNamespace = valueOrDefault(spec.DefaultGatewayNamespace, base.DefaultGatewayNamespace)
+	cfg.DefaultSectionName = valueOrDefault(spec.DefaultSectionName, base.DefaultSectionName)
+	cfg.HostnameTemplate = valueOrDefault(spec.HostnameTemplate, base.HostnameTemplate)
+	cfg.ClusterName = valueOrDefault(spec.ClusterName, base.ClusterName)
+	if spec.GatewayNamespaces != nil {
+		cfg.GatewayNamespaces = spec.GatewayNamespaces
+	}
+	if spec.AllowedHostnames != nil {
+		cfg.AllowedHostnames = spec.AllowedHostnames
+	}
+	if spec.DeniedHostnames != nil {
+		cfg.DeniedHostnames = spec.DeniedHostnames
+	}
+	if spec.DisableFinalizer != nil {
+		cfg.DisableFinalizer = *spec.DisableFinalizer
+	}
+	return cfg
+}
+
+// activeSpec reports cfg in the shape of a ControllerConfig spec.
+func activeSpec(cfg Config) httproutev1alpha1.ControllerConfigSpec {
+	disableFinalizer := cfg.DisableFinalizer
+	return httproutev1alpha1.ControllerConfigSpec{
+		DefaultGateway:          cfg.DefaultGateway,
+		DefaultGatewayNamespace: cfg.DefaultGatewayNamespace,
+		DefaultSectionName:      cfg.DefaultSectionName,
+		GatewayNamespaces:       cfg.GatewayNamespaces,
+		HostnameTemplate:        cfg.HostnameTemplate,
+		ClusterName:             cfg.ClusterName,
+		AllowedHostnames:        cfg.AllowedHostnames,
+		DeniedHostnames:         cfg.DeniedHostnames,
+		DisableFinalizer:        &disableFinalizer,
+	}
+}
+
+// SetupWithManager watches the ControllerConfig named Name. Status updates do
+// not change the generation and are ignored.
+func (r *ControllerConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&httproutev1alpha1.ControllerConfig{}, builder.WithPredicates(
+			predicate.NewPredicateFuncs(func(obj client.Object) bool { return obj.GetName() == r.Name }),
+			predicate.GenerationChangedPredicate{},
+		)).
+		Named("controllerconfig").
+		Complete(r)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("ControllerConfig", func() {
+	base := Config{
+		DefaultGateway:          "test-gateway",
+		DefaultGatewayNamespace: "envoy-gateway-system",
+		DefaultSectionName:      "https",
+	}
+
+	Context("When merging a ControllerConfig", func() {
+		It("should keep flag values for empty fields", func() {
+			disable := true
+			cfg := mergeControllerConfig(base, httproutev1alpha1.ControllerConfigSpec{
+				DefaultSectionName: "http",
+				DisableFinalizer:   &disable,
+			})
+
+			Expect(cfg.DefaultGateway).To(Equal("test-gateway"))
+			Expect(cfg.DefaultSectionName).To(Equal("http"))
+			Expect(cfg.DisableFinalizer).To(BeTrue())
+		})
+	})
+
+	Context("When the ControllerConfig CRD is not installed", func() {
+		It("should fall back to the flag configuration", func() {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			installed, err := ControllerConfigInstalled(mapper)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(installed).To(BeFalse())
+
+			mapper.Add(httproutev1alpha1.GroupVersion.WithKind("ControllerConfig"), meta.RESTScopeRoot)
+			Expect(ControllerConfigInstalled(mapper)).To(BeTrue())
+
+			reconciler := &ControllerConfigReconciler{Name: "default", Base: base, Store: NewConfigStore(base)}
+			Expect(reconciler.Load(context.Background(), noMatchReader{})).To(Succeed())
+			Expect(reconciler.Store.Get()).To(Equal(base))
+		})
+	})
+
+	Context("When several controllers read the configuration", func() {
+		It("should forward every change to each of them", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			changes := make(chan event.GenericEvent)
+			services, exposures := make(chan event.GenericEvent, 1), make(chan event.GenericEvent, 1)
+			go broadcastConfigChanges(ctx, changes, services, exposures)
+
+			changes <- event.GenericEvent{Object: &httproutev1alpha1.ControllerConfig{}}
+
+			Eventually(services).Should(Receive())
+			Eventually(exposures).Should(Receive())
+		})
+	})
+
+	Context("When the ControllerConfig changes", func() {
+		It("should apply valid changes and keep the configuration on invalid ones", func() {
+			ctx := context.Background()
+
+			// ARRANGE: ControllerConfig overriding the section name
+			changes := make(chan event.GenericEvent, 1)
+			reconciler := &ControllerConfigReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				Name:    "test-config",
+				Base:    base,
+				Store:   NewConfigStore(base),
+				Changes: changes,
+			}
+			cc := &httproutev1alpha1.ControllerConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-config"},
+				Spec: httproutev1alpha1.ControllerConfigSpec{
+					DefaultSectionName: "http",
+					DeniedHostnames:    []string{"login.example.com"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, cc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, cc) }()
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "test-config"}}
+
+			// ACT: Reconcile the ControllerConfig
+			_, err := reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: Configuration is applied, Services requeued and status reported
+			Expect(reconciler.Store.Get().DefaultSectionName).To(Equal("http"))
+			Expect(reconciler.Store.Get().DeniedHostnames).To(ConsistOf("login.example.com"))
+			Expect(changes).To(Receive())
+			Expect(k8sClient.Get(ctx, request.NamespacedName, cc)).Should(Succeed())
+			Expect(meta.IsStatusConditionTrue(cc.Status.Conditions, ConditionValid)).To(BeTrue())
+			Expect(cc.Status.Active.DefaultGateway).To(Equal("test-gateway"))
+
+			// ACT: Break the hostname template
+			cc.Spec.HostnameTemplate = "{{.Name"
+			Expect(k8sClient.Update(ctx, cc)).Should(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: Previous configuration stays in effect and the error is reported
+			Expect(reconciler.Store.Get().HostnameTemplate).To(BeEmpty())
+			Expect(changes).NotTo(Receive())
+			Expect(k8sClient.Get(ctx, request.NamespacedName, cc)).Should(Succeed())
+			cond := meta.FindStatusCondition(cc.Status.Conditions, ConditionValid)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(ReasonInvalidConfig))
+
+			// ACT: Delete the ControllerConfig
+			Expect(k8sClient.Delete(ctx, cc)).Should(Succeed())
+			_, err = reconciler.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: Flag configuration is restored
+			Expect(reconciler.Store.Get().DefaultSectionName).To(Equal("https"))
+			Expect(changes).To(Receive())
+		})
+	})
+})
+
+// noMatchReader fails like a client whose API server does not serve the kind
+type noMatchReader struct{ client.Reader }
+
+func (noMatchReader) Get(_ context.Context, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+	return &meta.NoKindMatchError{GroupKind: httproutev1alpha1.GroupVersion.WithKind("ControllerConfig").GroupKind()}
+}
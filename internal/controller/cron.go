@@ -0,0 +1,161 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchLimit bounds the search for the next window change, long enough
+// for windows that only open on February 29th
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// cronField is the range of one cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59}, {"hour", 0, 23}, {"day of month", 1, 31}, {"month", 1, 12}, {"day of week", 0, 7},
+}
+
+// cronWindow is a five-field cron expression read as an availability window:
+// every minute it matches is inside the window.
+type cronWindow struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set for "*", cron matches either day field when both are restricted
+	domAny, dowAny bool
+	location       *time.Location
+}
+
+// parseCronWindow parses "minute hour day-of-month month day-of-week",
+// optionally prefixed with "CRON_TZ=<zone> ". Fields accept *, numbers,
+// ranges, lists and steps, e.g. "* 8-17 * * 1-5".
+func parseCronWindow(expr string) (*cronWindow, error) {
+	w := &cronWindow{location: time.UTC}
+	expr = strings.TrimSpace(expr)
+	if zone, rest, ok := strings.Cut(expr, " "); ok && strings.HasPrefix(zone, "CRON_TZ=") {
+		location, err := time.LoadLocation(strings.TrimPrefix(zone, "CRON_TZ="))
+		if err != nil {
+			return nil, err
+		}
+		w.location, expr = location, rest
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(cronFields), len(fields))
+	}
+	sets := []*uint64{&w.minute, &w.hour, &w.dom, &w.month, &w.dow}
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		*sets[i] = set
+	}
+	// Sunday is 0 and 7
+	if w.dow&(1<<7) != 0 {
+		w.dow |= 1
+	}
+	w.domAny, w.dowAny = fields[2] == "*", fields[4] == "*"
+	return w, nil
+}
+
+// parseCronField returns the values a field matches as a bit set.
+func parseCronField(field string, f cronField) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		values, step, hasStep := strings.Cut(part, "/")
+		every := 1
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", step, f.name)
+			}
+			every = n
+		}
+		low, high := f.min, f.max
+		if values != "*" {
+			from, to, isRange := strings.Cut(values, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return 0, fmt.Errorf("invalid value %q in %s field", from, f.name)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(to); err != nil {
+					return 0, fmt.Errorf("invalid value %q in %s field", to, f.name)
+				}
+			} else if hasStep {
+				high = f.max
+			}
+		}
+		if low < f.min || high > f.max || low > high {
+			return 0, fmt.Errorf("%q is out of range %d-%d in %s field", part, f.min, f.max, f.name)
+		}
+		for v := low; v <= high; v += every {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// dayMatches reports whether the day of t is in the window.
+func (w *cronWindow) dayMatches(t time.Time) bool {
+	if w.month&(1<<int(t.Month())) == 0 {
+		return false
+	}
+	dom := w.dom&(1<<t.Day()) != 0
+	dow := w.dow&(1<<int(t.Weekday())) != 0
+	if w.domAny || w.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// contains reports whether the minute of t is in the window.
+func (w *cronWindow) contains(t time.Time) bool {
+	t = t.In(w.location)
+	return w.dayMatches(t) && w.hour&(1<<t.Hour()) != 0 && w.minute&(1<<t.Minute()) != 0
+}
+
+// nextChange returns when the window next opens, if it is closed at now, or
+// closes, if it is open. It returns the zero time when that does not happen
+// within cronSearchLimit.
+func (w *cronWindow) nextChange(now time.Time) time.Time {
+	open := w.contains(now)
+	t := now.In(w.location).Truncate(time.Minute).Add(time.Minute)
+	nextDay := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, w.location) }
+	nextHour := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, w.location)
+	}
+	allMinutes := bits.OnesCount64(w.minute) == 60
+	allHours := bits.OnesCount64(w.hour) == 24
+
+	for limit := now.Add(cronSearchLimit); t.Before(limit); {
+		switch {
+		case w.contains(t) != open:
+			return t
+		case !open && !w.dayMatches(t):
+			t = nextDay(t)
+		case !open && w.hour&(1<<t.Hour()) == 0:
+			t = nextHour(t)
+		case open && allHours && allMinutes:
+			t = nextDay(t)
+		case open && allMinutes:
+			t = nextHour(t)
+		default:
+			t = t.Add(time.Minute)
+		}
+	}
+	return time.Time{}
+}
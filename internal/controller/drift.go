@@ -0,0 +1,72 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// driftCorrections counts generated resources restored after changes made outside the controller
+var driftCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "httproute_controller_drift_corrections_total",
+	Help: "Generated resources restored after they were modified or deleted outside the controller",
+}, []string{"kind", "drift"})
+
+func init() {
+	metrics.Registry.MustRegister(driftCorrections)
+}
+
+// attachedMessage is the Exposed condition message for a route attached to target.
+func attachedMessage(target gatewayTarget) string {
+	return fmt.Sprintf("HTTPRoute attached to %s/%s section %s", target.Namespace, target.Name, target.SectionName)
+}
+
+// attachedTo reports whether svc already reports its route attached to target.
+func attachedTo(svc *corev1.Service, target gatewayTarget) bool {
+	cond := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed)
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Message == attachedMessage(target)
+}
+
+// recordApplied emits an event for a generated resource that was written and
+// counts writes that reverted drift.
+func (r *ServiceReconciler) recordApplied(source client.Object, kind, key string, result applyResult) {
+	switch result {
+	case applyCreated, applyUpdated:
+		r.recordEvent(source, corev1.EventTypeNormal, kind+"Reconciled", fmt.Sprintf("%s %s", kind, key))
+	case applyReverted, applyRecreated:
+		drift := "modified"
+		if result == applyRecreated {
+			drift = "deleted"
+		}
+		driftCorrections.WithLabelValues(kind, drift).Inc()
+		r.recordEvent(source, corev1.EventTypeWarning, kind+"DriftReverted",
+			fmt.Sprintf("%s %s was %s outside the controller and has been restored", kind, key, drift))
+	}
+}
+
+// requestForGeneratedResource maps a generated resource to its source Service.
+func (r *ServiceReconciler) requestForGeneratedResource(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[LabelManagedBy] != ManagedByValue || labels[LabelServiceName] == "" ||
+		!r.ownsGenerated(obj) || !r.watchesNamespace(labels[LabelServiceNamespace]) {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: labels[LabelServiceNamespace],
+		Name:      labels[LabelServiceName],
+	}}}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Drift correction", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When a generated HTTPRoute is changed outside the controller", func() {
+		It("should restore the edited and the deleted route", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Exposed Service with a reconciled route
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-drift",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "drift.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-drift"),
+				Namespace: "envoy-gateway-system",
+			}
+			hostnames := func() []gatewayv1.Hostname {
+				current := &gatewayv1.HTTPRoute{}
+				if err := k8sClient.Get(ctx, routeKey, current); err != nil {
+					return nil
+				}
+				return current.Spec.Hostnames
+			}
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, routeKey, route); err != nil {
+					return ""
+				}
+				return route.Annotations[AnnotationAppliedGeneration]
+			}, timeout, interval).ShouldNot(BeEmpty())
+			modified := testutil.ToFloat64(driftCorrections.WithLabelValues("HTTPRoute", "modified"))
+			deleted := testutil.ToFloat64(driftCorrections.WithLabelValues("HTTPRoute", "deleted"))
+
+			// ACT: Edit the route
+			route.Spec.Hostnames = []gatewayv1.Hostname{"hijacked.example.com"}
+			Expect(k8sClient.Update(ctx, route)).Should(Succeed())
+
+			// ASSERT: Hostname is restored and counted
+			Eventually(hostnames, timeout, interval).Should(ConsistOf(gatewayv1.Hostname("drift.example.com")))
+			Eventually(func() float64 {
+				return testutil.ToFloat64(driftCorrections.WithLabelValues("HTTPRoute", "modified"))
+			}, timeout, interval).Should(BeNumerically(">", modified))
+
+			// ACT: Delete the route
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, route)).Should(Succeed())
+
+			// ASSERT: Route is recreated and counted
+			Eventually(func() bool {
+				current := &gatewayv1.HTTPRoute{}
+				return k8sClient.Get(ctx, routeKey, current) == nil && current.UID != route.UID
+			}, timeout, interval).Should(BeTrue())
+			Eventually(func() float64 {
+				return testutil.ToFloat64(driftCorrections.WithLabelValues("HTTPRoute", "deleted"))
+			}, timeout, interval).Should(BeNumerically(">", deleted))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// AnnotationURL publishes the URL the Service is reachable at once its route is accepted
+	AnnotationURL = AnnotationPrefix + "/url"
+	// AnnotationGatewayAddress publishes the addresses of the Gateway serving the route
+	AnnotationGatewayAddress = AnnotationPrefix + "/gateway-address"
+)
+
+// publishEndpoint annotates the Service with its URL and gateway addresses
+// once the gateway controller accepted the route, and removes them otherwise.
+func (r *ServiceReconciler) publishEndpoint(
+	ctx context.Context, svc *corev1.Service, hostname string, target gatewayTarget,
+) error {
+	if !meta.IsStatusConditionTrue(svc.Status.Conditions, ConditionHTTPRouteAccepted) {
+		return r.recordEndpoint(ctx, svc, "", "")
+	}
+
+	gw := &gatewayv1.Gateway{}
+	if err := r.Get(ctx, types.NamespacedName{Name: target.Name, Namespace: target.Namespace}, gw); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return r.recordEndpoint(ctx, svc, "", "")
+	}
+	for _, listener := range gw.Spec.Listeners {
+		if string(listener.Name) == target.SectionName {
+			return r.recordEndpoint(ctx, svc, endpointURL(listener, hostname), gatewayAddresses(gw))
+		}
+	}
+	return r.recordEndpoint(ctx, svc, "", "")
+}
+
+// endpointURL returns the URL of hostname on listener, omitting the default
+// port of its scheme. A wildcard hostname has no URL.
+func endpointURL(listener gatewayv1.Listener, hostname string) string {
+	if strings.HasPrefix(hostname, "*") {
+		return ""
+	}
+	scheme, defaultPort := "http", gatewayv1.PortNumber(80)
+	if listener.Protocol == gatewayv1.HTTPSProtocolType {
+		scheme, defaultPort = "https", 443
+	}
+	if listener.Port == defaultPort {
+		return fmt.Sprintf("%s://%s", scheme, hostname)
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, hostname, listener.Port)
+}
+
+// gatewayAddresses returns the comma separated IPs and hostnames the Gateway reports.
+func gatewayAddresses(gw *gatewayv1.Gateway) string {
+	addresses := make([]string, 0, len(gw.Status.Addresses))
+	for _, address := range gw.Status.Addresses {
+		addresses = append(addresses, address.Value)
+	}
+	return strings.Join(addresses, ",")
+}
+
+// recordEndpoint keeps AnnotationURL and AnnotationGatewayAddress in sync,
+// removing empty values.
+func (r *ServiceReconciler) recordEndpoint(ctx context.Context, svc *corev1.Service, url, address string) error {
+	return r.patchAnnotations(ctx, svc, map[string]string{AnnotationURL: url, AnnotationGatewayAddress: address})
+}
+
+// gatewayAddressesChanged passes Gateway updates that change the reported
+// addresses, which do not bump the generation.
+var gatewayAddressesChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldGateway, ok := e.ObjectOld.(*gatewayv1.Gateway)
+		if !ok {
+			return false
+		}
+		newGateway, ok := e.ObjectNew.(*gatewayv1.Gateway)
+		if !ok {
+			return false
+		}
+		return !equality.Semantic.DeepEqual(oldGateway.Status.Addresses, newGateway.Status.Addresses)
+	},
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Published endpoint", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When building the URL", func() {
+		It("should omit the default port of the listener protocol", func() {
+			https := gatewayv1.Listener{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType}
+			Expect(endpointURL(https, "app.example.com")).To(Equal("https://app.example.com"))
+
+			http := gatewayv1.Listener{Name: "http", Port: 8080, Protocol: gatewayv1.HTTPProtocolType}
+			Expect(endpointURL(http, "app.example.com")).To(Equal("http://app.example.com:8080"))
+		})
+	})
+
+	Context("When the hostname is a wildcard", func() {
+		It("should not publish a URL", func() {
+			https := gatewayv1.Listener{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType}
+			Expect(endpointURL(https, "*.example.com")).To(BeEmpty())
+		})
+	})
+
+	Context("When the gateway controller accepts the route", func() {
+		It("should annotate the Service with the URL and gateway address", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Gateway with an HTTP listener on a custom port and an address
+			gw := &gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "endpoint-gateway",
+					Namespace: "custom-ns",
+				},
+				Spec: gatewayv1.GatewaySpec{
+					GatewayClassName: "test-class",
+					Listeners: []gatewayv1.Listener{
+						{
+							Name:     "web",
+							Port:     8080,
+							Protocol: gatewayv1.HTTPProtocolType,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, gw)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, gw) }()
+			ipAddress := gatewayv1.IPAddressType
+			gw.Status.Addresses = []gatewayv1.GatewayStatusAddress{{Type: &ipAddress, Value: "192.0.2.10"}}
+			Expect(k8sClient.Status().Update(ctx, gw)).Should(Succeed())
+
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-endpoint",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":            "true",
+						"httproute.controller/hostname":          "endpoint.example.com",
+						"httproute.controller/gateway":           "endpoint-gateway",
+						"httproute.controller/gateway-namespace": "custom-ns",
+						"httproute.controller/section-name":      "web",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			annotations := func() map[string]string {
+				current := &corev1.Service{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, current); err != nil {
+					return nil
+				}
+				return current.Annotations
+			}
+
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{Name: routeName("default", "test-svc-endpoint"), Namespace: "custom-ns"}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, route)
+			}, timeout, interval).Should(Succeed())
+
+			// ASSERT: Nothing is published before the route is accepted
+			Consistently(annotations, time.Second, interval).ShouldNot(HaveKey(AnnotationURL))
+
+			// ACT: Gateway controller accepts the route
+			sectionName := gatewayv1.SectionName("web")
+			route.Status.Parents = []gatewayv1.RouteParentStatus{{
+				ParentRef:      gatewayv1.ParentReference{Name: "endpoint-gateway", SectionName: &sectionName},
+				ControllerName: "example.com/gateway-controller",
+				Conditions: []metav1.Condition{{
+					Type:               string(gatewayv1.RouteConditionAccepted),
+					Status:             metav1.ConditionTrue,
+					Reason:             string(gatewayv1.RouteReasonAccepted),
+					Message:            "accepted",
+					LastTransitionTime: metav1.Now(),
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, route)).Should(Succeed())
+
+			// ASSERT: URL and address are published
+			Eventually(annotations, timeout, interval).Should(And(
+				HaveKeyWithValue(AnnotationURL, "http://endpoint.example.com:8080"),
+				HaveKeyWithValue(AnnotationGatewayAddress, "192.0.2.10"),
+			))
+
+			// ACT: Gateway address changes
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: gw.Name, Namespace: gw.Namespace}, gw)).Should(Succeed())
+			gw.Status.Addresses[0].Value = "192.0.2.20"
+			Expect(k8sClient.Status().Update(ctx, gw)).Should(Succeed())
+
+			// ASSERT: Published address follows the Gateway
+			Eventually(annotations, timeout, interval).Should(
+				HaveKeyWithValue(AnnotationGatewayAddress, "192.0.2.20"))
+		})
+	})
+})
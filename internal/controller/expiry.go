@@ -0,0 +1,130 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// AnnotationExpiresAt removes the route at an RFC 3339 time, e.g. "2025-07-01T18:00:00Z"
+	AnnotationExpiresAt = AnnotationPrefix + "/expires-at"
+	// AnnotationExposeTTL removes the route once it has existed for a
+	// duration, e.g. "72h"
+	AnnotationExposeTTL = AnnotationPrefix + "/expose-ttl"
+	// AnnotationExposeWindow only routes during the minutes matched by a cron
+	// expression, e.g. "* 8-17 * * 1-5" or "CRON_TZ=Europe/Berlin * 8-17 * * 1-5"
+	AnnotationExposeWindow = AnnotationPrefix + "/expose-window"
+	// ReasonExpired means expires-at or expose-ttl has passed
+	ReasonExpired = "Expired"
+	// ReasonOutsideWindow means the current time is outside expose-window
+	ReasonOutsideWindow = "OutsideWindow"
+	// ReasonInvalidSchedule means expires-at, expose-ttl or expose-window cannot be parsed
+	ReasonInvalidSchedule = "InvalidSchedule"
+
+	// ConditionExposureStarted is True once the route was first created; its
+	// lastTransitionTime is when, expose-ttl counts from it. It is kept while
+	// the exposure is blocked and removed when the Service is no longer exposed.
+	ConditionExposureStarted = "ExposureStarted"
+	// ReasonRouteCreated means the route of the exposure was created
+	ReasonRouteCreated = "RouteCreated"
+)
+
+// exposureStartedMessage is the message of the ExposureStarted condition
+const exposureStartedMessage = "expose-ttl counts from the creation of the first route"
+
+// exposureSchedule holds the expiry and window annotations of an exposure
+type exposureSchedule struct {
+	ExpiresAt string
+	TTL       string
+	Window    string
+}
+
+// checkSchedule returns the reason and message when schedule forbids a route
+// at now for an exposure that started at started, and after how long the
+// decision changes (zero when it never does).
+func checkSchedule(schedule exposureSchedule, started, now time.Time) (string, string, time.Duration) {
+	var expiry time.Time
+	if schedule.ExpiresAt != "" {
+		t, err := time.Parse(time.RFC3339, schedule.ExpiresAt)
+		if err != nil {
+			return ReasonInvalidSchedule, fmt.Sprintf("invalid expires-at %q: %v", schedule.ExpiresAt, err), 0
+		}
+		expiry = t
+	}
+	if schedule.TTL != "" {
+		ttl, err := time.ParseDuration(schedule.TTL)
+		if err != nil || ttl <= 0 {
+			return ReasonInvalidSchedule, fmt.Sprintf("invalid expose-ttl %q, expected a positive duration", schedule.TTL), 0
+		}
+		if t := started.Add(ttl); expiry.IsZero() || t.Before(expiry) {
+			expiry = t
+		}
+	}
+	if !expiry.IsZero() && !now.Before(expiry) {
+		return ReasonExpired, fmt.Sprintf("exposure expired at %s", expiry.UTC().Format(time.RFC3339)), 0
+	}
+
+	var requeue time.Duration
+	if !expiry.IsZero() {
+		requeue = expiry.Sub(now)
+	}
+	if schedule.Window == "" {
+		return "", "", requeue
+	}
+	window, err := parseCronWindow(schedule.Window)
+	if err != nil {
+		return ReasonInvalidSchedule, fmt.Sprintf("invalid expose-window %q: %v", schedule.Window, err), 0
+	}
+	change := window.nextChange(now)
+	if !change.IsZero() && (requeue == 0 || change.Sub(now) < requeue) {
+		requeue = change.Sub(now)
+	}
+	if !window.contains(now) {
+		if change.IsZero() {
+			return ReasonOutsideWindow, fmt.Sprintf("expose-window %q never opens", schedule.Window), requeue
+		}
+		return ReasonOutsideWindow, fmt.Sprintf("outside expose-window %q until %s",
+			schedule.Window, change.UTC().Format(time.RFC3339)), requeue
+	}
+	return "", "", requeue
+}
+
+// checkExposureSchedule applies checkSchedule to svc. The TTL and window can
+// be defaulted like the other settings, e.g. for all Services of a preview Namespace.
+func (r *ServiceReconciler) checkExposureSchedule(
+	ctx context.Context, svc *corev1.Service,
+) (string, string, time.Duration, error) {
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return "", "", 0, err
+	}
+	now := time.Now()
+	reason, message, requeue := checkSchedule(exposureSchedule{
+		ExpiresAt: r.annotation(svc, AnnotationExpiresAt),
+		TTL:       r.setting(s, AnnotationExposeTTL),
+		Window:    r.setting(s, AnnotationExposeWindow),
+	}, exposureStarted(svc.Status.Conditions, now), now)
+	return reason, message, requeue, nil
+}
+
+// exposureStarted returns when the route was first created according to the
+// ExposureStarted condition, or now when it has not been yet. Only the
+// controller writes the status, unlike annotations tenants can edit.
+func exposureStarted(conditions []metav1.Condition, now time.Time) time.Time {
+	if cond := meta.FindStatusCondition(conditions, ConditionExposureStarted); cond != nil &&
+		cond.Status == metav1.ConditionTrue {
+		return cond.LastTransitionTime.Time
+	}
+	return now
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("Exposure expiry", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	// Friday 17:30 UTC
+	now := time.Date(2025, 6, 6, 17, 30, 0, 0, time.UTC)
+
+	Context("When parsing availability windows", func() {
+		It("should find when the window opens and closes", func() {
+			weekdays, err := parseCronWindow("* 8-17 * * 1-5")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(weekdays.contains(now)).To(BeTrue())
+			Expect(weekdays.nextChange(now)).To(Equal(time.Date(2025, 6, 6, 18, 0, 0, 0, time.UTC)))
+			saturday := time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC)
+			Expect(weekdays.contains(saturday)).To(BeFalse())
+			Expect(weekdays.nextChange(saturday)).To(Equal(time.Date(2025, 6, 9, 8, 0, 0, 0, time.UTC)))
+
+			always, err := parseCronWindow("* * * * *")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(always.nextChange(now).IsZero()).To(BeTrue())
+
+			berlin, err := parseCronWindow("CRON_TZ=Europe/Berlin */15 9 * * *")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(berlin.nextChange(now).UTC()).To(Equal(time.Date(2025, 6, 7, 7, 0, 0, 0, time.UTC)))
+
+			for _, invalid := range []string{"* * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+				_, err := parseCronWindow(invalid)
+				Expect(err).To(HaveOccurred(), invalid)
+			}
+		})
+	})
+
+	Context("When checking the schedule", func() {
+		It("should expire at the earliest deadline and requeue before the next change", func() {
+			started := now.Add(-time.Hour)
+
+			reason, _, requeue := checkSchedule(exposureSchedule{TTL: "2h"}, started, now)
+			Expect(reason).To(BeEmpty())
+			Expect(requeue).To(Equal(time.Hour))
+
+			reason, _, _ = checkSchedule(exposureSchedule{TTL: "30m"}, started, now)
+			Expect(reason).To(Equal(ReasonExpired))
+
+			reason, _, requeue = checkSchedule(exposureSchedule{
+				ExpiresAt: "2025-06-06T17:40:00Z", TTL: "2h", Window: "* 8-17 * * 1-5",
+			}, started, now)
+			Expect(reason).To(BeEmpty())
+			Expect(requeue).To(Equal(10 * time.Minute))
+
+			reason, _, requeue = checkSchedule(exposureSchedule{Window: "* 8-17 * * 1-5"}, started,
+				time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC))
+			Expect(reason).To(Equal(ReasonOutsideWindow))
+			Expect(requeue).To(Equal(44 * time.Hour))
+
+			reason, _, _ = checkSchedule(exposureSchedule{ExpiresAt: "tomorrow"}, started, now)
+			Expect(reason).To(Equal(ReasonInvalidSchedule))
+		})
+	})
+
+	Context("When an exposure expires", func() {
+		It("should remove the route and keep the Service", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Exposed Service
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-expiry",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "expiry.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{
+						Name:       "http",
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-expiry"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{})
+			}, timeout, interval).Should(Succeed())
+
+			// ACT: Set an expiry in the past
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			svc.Annotations["httproute.controller/expires-at"] = "2020-01-01T00:00:00Z"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+
+			// ASSERT: Route is removed, the Service stays with Exposed False
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{}))
+			}, timeout, interval).Should(BeTrue())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			Expect(meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed).Reason).To(Equal(ReasonExpired))
+		})
+	})
+
+	Context("When a Service has a TTL", func() {
+		It("should requeue when it expires", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service of class expiry, ignored by the unclassed suite controller
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-ttl",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":     "true",
+						"httproute.controller/hostname":   "ttl.example.com",
+						"httproute.controller/class":      "expiry",
+						"httproute.controller/expose-ttl": "1h",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{
+						Name:       "http",
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+			reconciler := &ServiceReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Config: Config{
+					DefaultGateway:          "test-gateway",
+					DefaultGatewayNamespace: "envoy-gateway-system",
+					DefaultSectionName:      "https",
+					ControllerClass:         "expiry",
+				},
+			}
+
+			// ACT: Reconcile
+			result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(svc)})
+
+			// ASSERT: Requeued no later than the TTL, counted from the recorded exposure
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+			Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			started := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposureStarted)
+			Expect(started).NotTo(BeNil())
+			Expect(started.LastTransitionTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+
+			// ACT: An annotation cannot move the start of the TTL
+			svc.Annotations["httproute.controller/exposed-at"] = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+			_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(svc)})
+			Expect(err).NotTo(HaveOccurred())
+
+			// ACT: Pretend the route was created two hours ago
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			started = meta.FindStatusCondition(svc.Status.Conditions, ConditionExposureStarted)
+			started.LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+			Expect(k8sClient.Status().Update(ctx, svc)).Should(Succeed())
+			_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(svc)})
+
+			// ASSERT: Expired although the Service is new
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			Expect(meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed).Reason).To(Equal(ReasonExpired))
+		})
+	})
+
+	Context("When an exposure is blocked by its schedule", func() {
+		It("should release its hostname claim", func() {
+			svc := &corev1.Service{Status: corev1.ServiceStatus{Conditions: []metav1.Condition{{
+				Type:   ConditionExposed,
+				Status: metav1.ConditionFalse,
+				Reason: ReasonOutsideWindow,
+			}}}}
+			Expect(holdsHostnameClaim(svc)).To(BeFalse())
+
+			svc.Status.Conditions[0].Reason = ReasonPendingApproval
+			Expect(holdsHostnameClaim(svc)).To(BeTrue())
+
+			es := &httproutev1alpha1.ExposedService{Status: httproutev1alpha1.ExposedServiceStatus{
+				Conditions: []metav1.Condition{{Type: ConditionExposed, Status: metav1.ConditionFalse, Reason: ReasonExpired}},
+			}}
+			Expect(holdsHostnameClaim(es)).To(BeFalse())
+		})
+	})
+})
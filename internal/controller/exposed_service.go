@@ -0,0 +1,490 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+// Labels recording the source ExposedService on generated resources
+const (
+	LabelExposedServiceNamespace = AnnotationPrefix + "/exposedservice-namespace"
+	LabelExposedServiceName      = AnnotationPrefix + "/exposedservice-name"
+)
+
+const (
+	// ReasonServiceNotFound means the Service an ExposedService points at does not exist
+	ReasonServiceNotFound = "ServiceNotFound"
+	// ReasonPortNotFound means neither the ExposedService nor the Service sets a port
+	ReasonPortNotFound = "PortNotFound"
+)
+
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposedservices,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposedservices/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposedservices/finalizers,verbs=update
+
+// exposedServiceLabels returns the labels of resources generated for es.
+func (r *ServiceReconciler) exposedServiceLabels(es *httproutev1alpha1.ExposedService) map[string]string {
+	generated := map[string]string{
+		LabelManagedBy:               ManagedByValue,
+		LabelExposedServiceNamespace: es.Namespace,
+		LabelExposedServiceName:      es.Name,
+	}
+	if r.config().ControllerClass != "" {
+		generated[LabelClass] = r.config().ControllerClass
+	}
+	return generated
+}
+
+// exposedGatewayTargets returns the listeners es attaches to, with gaps filled
+// from the controller defaults.
+func (r *ServiceReconciler) exposedGatewayTargets(es *httproutev1alpha1.ExposedService) []gatewayTarget {
+	refs := es.Spec.GatewayRefs
+	if len(refs) == 0 {
+		refs = []httproutev1alpha1.GatewayReference{{}}
+	}
+	targets := make([]gatewayTarget, 0, len(refs))
+	for _, ref := range refs {
+		targets = append(targets, gatewayTarget{
+			Name:        valueOrDefault(string(ref.Name), r.config().DefaultGateway),
+			Namespace:   valueOrDefault(string(ref.Namespace), r.config().DefaultGatewayNamespace),
+			SectionName: valueOrDefault(string(ref.SectionName), r.config().DefaultSectionName),
+		})
+	}
+	return targets
+}
+
+// reconcileExposedService applies the HTTPRoute and ReferenceGrant described
+// by an ExposedService and reports their state in its status.
+func (r *ServiceReconciler) reconcileExposedService(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	es := &httproutev1alpha1.ExposedService{}
+	if err := r.Get(ctx, req.NamespacedName, es); err != nil {
+		if errors.IsNotFound(err) {
+			// Without the finalizer the ExposedService may be gone before cleanup ran
+			_, err := r.pruneExposedResources(ctx, req.NamespacedName, nil)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, err
+	}
+	if managed, err := r.managesNamespace(ctx, es.Namespace); err != nil || !managed {
+		return ctrl.Result{}, err
+	}
+
+	if !es.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(es, r.finalizer()) {
+			return ctrl.Result{}, nil
+		}
+		if _, err := r.pruneExposedResources(ctx, req.NamespacedName, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		controllerutil.RemoveFinalizer(es, r.finalizer())
+		return ctrl.Result{}, r.Update(ctx, es)
+	}
+	if err := r.syncExposedFinalizer(ctx, es); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	original := es.DeepCopy()
+	result, err := r.exposeService(ctx, es)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	es.Status.ObservedGeneration = es.Generation
+	if equality.Semantic.DeepEqual(original.Status, es.Status) {
+		return result, nil
+	}
+	return result, r.Status().Patch(ctx, es, client.MergeFrom(original))
+}
+
+// syncExposedFinalizer adds the finalizer to es unless finalizers are disabled.
+func (r *ServiceReconciler) syncExposedFinalizer(ctx context.Context, es *httproutev1alpha1.ExposedService) error {
+	var changed bool
+	if r.config().DisableFinalizer {
+		changed = controllerutil.RemoveFinalizer(es, r.finalizer())
+	} else {
+		changed = controllerutil.AddFinalizer(es, r.finalizer())
+	}
+	if !changed {
+		return nil
+	}
+	return r.Update(ctx, es)
+}
+
+// exposeService applies the generated resources of es and sets its status
+// conditions and URL; the caller patches the status.
+func (r *ServiceReconciler) exposeService(
+	ctx context.Context, es *httproutev1alpha1.ExposedService,
+) (ctrl.Result, error) {
+	now := time.Now()
+	reason, message, requeue := checkSchedule(exposureSchedule{
+		ExpiresAt: r.annotation(es, AnnotationExpiresAt),
+		TTL:       r.annotation(es, AnnotationExposeTTL),
+		Window:    r.annotation(es, AnnotationExposeWindow),
+	}, exposureStarted(es.Status.Conditions, now), now)
+	if reason != "" {
+		return ctrl.Result{RequeueAfter: requeue}, r.withholdExposure(ctx, es, reason, message)
+	}
+
+	svc := &corev1.Service{}
+	err := r.Get(ctx, types.NamespacedName{Name: es.Spec.ServiceName, Namespace: es.Namespace}, svc)
+	if errors.IsNotFound(err) {
+		return ctrl.Result{}, r.withholdExposure(ctx, es, ReasonServiceNotFound,
+			fmt.Sprintf("Service %s/%s not found", es.Namespace, es.Spec.ServiceName))
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for _, hostname := range es.Spec.Hostnames {
+		violation, err := r.checkHostnamePolicy(ctx, svc, string(hostname))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if violation != "" {
+			return ctrl.Result{}, r.withholdExposure(ctx, es, ReasonHostnameDenied, violation)
+		}
+	}
+	// Only the first claimant of a hostname, Service or ExposedService, gets a route
+	for _, hostname := range es.Spec.Hostnames {
+		conflict, err := r.hostnameConflict(ctx, es, string(hostname))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if conflict != "" {
+			return ctrl.Result{}, r.withholdExposure(ctx, es, ReasonHostnameConflict, conflict)
+		}
+	}
+
+	port := es.Spec.Port
+	if port == 0 && len(svc.Spec.Ports) > 0 {
+		port = svc.Spec.Ports[0].Port
+	}
+	if port == 0 {
+		return ctrl.Result{}, r.withholdExposure(ctx, es, ReasonPortNotFound,
+			fmt.Sprintf("Service %s/%s has no ports", es.Namespace, es.Spec.ServiceName))
+	}
+
+	targets := r.exposedGatewayTargets(es)
+	hostnames := make([]string, 0, len(es.Spec.Hostnames))
+	for _, hostname := range es.Spec.Hostnames {
+		hostnames = append(hostnames, string(hostname))
+	}
+	// The route attaches to every target, each must satisfy the policies
+	for _, target := range targets {
+		denied, err := r.checkExposurePolicies(ctx, svc, policyRoute{
+			Hostnames:        hostnames,
+			Gateway:          target.Name,
+			GatewayNamespace: target.Namespace,
+			SectionName:      target.SectionName,
+			Port:             port,
+		})
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if denied != "" {
+			return ctrl.Result{}, r.withholdExposure(ctx, es, ReasonExposureDenied, denied)
+		}
+	}
+
+	pending, approved, err := r.checkApproval(ctx, es, "ExposedService", targets, hostnames, port)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if pending != "" {
+		if err := r.withholdExposure(ctx, es, ReasonPendingApproval, pending); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.setExposedCondition(es, ConditionPendingApproval, metav1.ConditionTrue, ReasonPendingApproval, pending)
+		return r.approvalResult(), nil
+	}
+
+	if err := r.applyExposedResources(ctx, es, targets, port); err != nil {
+		if conflict, ok := err.(*ownershipConflictError); ok {
+			if conditionChanges(es.Status.Conditions, ConditionExposed, metav1.ConditionFalse,
+				ReasonResourceConflict, conflict.Error()) {
+				r.recordEvent(es, corev1.EventTypeWarning, ReasonResourceConflict, conflict.Error())
+			}
+			r.setExposedCondition(es, ConditionExposed, metav1.ConditionFalse, ReasonResourceConflict, conflict.Error())
+			return ctrl.Result{RequeueAfter: resourceConflictRequeue}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	meta.RemoveStatusCondition(&es.Status.Conditions, ConditionPendingApproval)
+	r.setExposedCondition(es, ConditionExposureStarted, metav1.ConditionTrue, ReasonRouteCreated, exposureStartedMessage)
+	if approved == "" {
+		meta.RemoveStatusCondition(&es.Status.Conditions, ConditionApproved)
+	} else {
+		r.setExposedCondition(es, ConditionApproved, metav1.ConditionTrue, ReasonWebhookApproved, approved)
+	}
+	r.setExposedCondition(es, ConditionExposed, metav1.ConditionTrue, ReasonReconciled, attachedMessage(targets[0]))
+	return ctrl.Result{RequeueAfter: recheckApproval(es.UID, requeue)}, r.syncExposedRouteStatus(ctx, es, targets[0])
+}
+
+// withholdExposure reports why es gets no route and removes its generated resources.
+func (r *ServiceReconciler) withholdExposure(
+	ctx context.Context, es *httproutev1alpha1.ExposedService, reason, message string,
+) error {
+	if conditionChanges(es.Status.Conditions, ConditionExposed, metav1.ConditionFalse, reason, message) {
+		r.recordEvent(es, corev1.EventTypeWarning, reason, message)
+	}
+	r.setExposedCondition(es, ConditionExposed, metav1.ConditionFalse, reason, message)
+	meta.RemoveStatusCondition(&es.Status.Conditions, ConditionPendingApproval)
+	meta.RemoveStatusCondition(&es.Status.Conditions, ConditionApproved)
+	meta.RemoveStatusCondition(&es.Status.Conditions, ConditionHTTPRouteAccepted)
+	meta.RemoveStatusCondition(&es.Status.Conditions, ConditionHTTPRouteResolvedRefs)
+	es.Status.URL = ""
+	deleted, err := r.pruneExposedResources(ctx, client.ObjectKeyFromObject(es), nil)
+	r.recordDeleted(es, deleted)
+	return err
+}
+
+// applyExposedResources creates or updates the HTTPRoute and ReferenceGrant of
+// es and removes generated resources that no longer match them.
+func (r *ServiceReconciler) applyExposedResources(
+	ctx context.Context, es *httproutev1alpha1.ExposedService, targets []gatewayTarget, port int32,
+) error {
+	route := newHTTPRoute(routeParams{
+		Name:      exposedRouteName(es.Namespace, es.Name),
+		Namespace: targets[0].Namespace,
+		Labels:    r.exposedServiceLabels(es),
+		Parents:   targets,
+		Hostnames: es.Spec.Hostnames,
+		Service:   types.NamespacedName{Name: es.Spec.ServiceName, Namespace: es.Namespace},
+		Port:      port,
+		Rules:     es.Spec.Rules,
+	})
+	result, err := r.applyExposedResource(ctx, es, "HTTPRoute", route, &gatewayv1.HTTPRoute{})
+	if err != nil {
+		return err
+	}
+	r.recordApplied(es, "HTTPRoute", client.ObjectKeyFromObject(route).String(), result)
+
+	var grant *gatewayv1beta1.ReferenceGrant
+	if !es.Spec.SkipReferenceGrant {
+		grant = newReferenceGrant(exposedReferenceGrantName(es.Namespace, es.Name), es.Namespace,
+			r.exposedServiceLabels(es), targets[0].Namespace, es.Spec.ServiceName)
+		if err := controllerutil.SetControllerReference(es, grant, r.Scheme); err != nil {
+			return err
+		}
+		result, err := r.applyExposedResource(ctx, es, "ReferenceGrant", grant, &gatewayv1beta1.ReferenceGrant{})
+		if err != nil {
+			return err
+		}
+		r.recordApplied(es, "ReferenceGrant", client.ObjectKeyFromObject(grant).String(), result)
+	}
+
+	deleted, err := r.pruneExposedResources(ctx, client.ObjectKeyFromObject(es), func(obj client.Object) bool {
+		switch obj.(type) {
+		case *gatewayv1.HTTPRoute:
+			return client.ObjectKeyFromObject(obj) == client.ObjectKeyFromObject(route)
+		case *gatewayv1beta1.ReferenceGrant:
+			return grant != nil && client.ObjectKeyFromObject(obj) == client.ObjectKeyFromObject(grant)
+		}
+		return true
+	})
+	r.recordDeleted(es, deleted)
+	return err
+}
+
+// applyExposedResource applies desired unless an unmanaged resource holds its name.
+func (r *ServiceReconciler) applyExposedResource(
+	ctx context.Context, es *httproutev1alpha1.ExposedService, kind string, desired, existing client.Object,
+) (applyResult, error) {
+	err := r.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if errors.IsNotFound(err) {
+		return r.applyGenerated(ctx, desired, nil)
+	}
+	if err != nil {
+		return applyUnchanged, err
+	}
+	if !r.ownsExposedResource(existing, es) {
+		return applyUnchanged, &ownershipConflictError{
+			kind: kind, key: client.ObjectKeyFromObject(existing), adopt: r.key(AnnotationAdopt), source: "ExposedService",
+		}
+	}
+	return r.applyGenerated(ctx, desired, existing)
+}
+
+// ownsExposedResource reports whether existing was generated for es or es
+// explicitly adopts it.
+func (r *ServiceReconciler) ownsExposedResource(existing client.Object, es *httproutev1alpha1.ExposedService) bool {
+	labels := existing.GetLabels()
+	if labels[LabelManagedBy] == ManagedByValue &&
+		labels[LabelExposedServiceNamespace] == es.Namespace && labels[LabelExposedServiceName] == es.Name {
+		return true
+	}
+	return appliedBy(existing) || r.annotation(es, AnnotationAdopt) == "true"
+}
+
+// pruneExposedResources deletes the resources generated for the ExposedService
+// key that keep rejects and returns the deleted ones.
+func (r *ServiceReconciler) pruneExposedResources(
+	ctx context.Context, key types.NamespacedName, keep func(client.Object) bool,
+) ([]client.Object, error) {
+	matching := client.MatchingLabels{
+		LabelManagedBy:               ManagedByValue,
+		LabelExposedServiceNamespace: key.Namespace,
+		LabelExposedServiceName:      key.Name,
+	}
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := r.List(ctx, routes, matching); err != nil {
+		return nil, err
+	}
+	grants := &gatewayv1beta1.ReferenceGrantList{}
+	if err := r.List(ctx, grants, matching, client.InNamespace(key.Namespace)); err != nil {
+		return nil, err
+	}
+	objects := make([]client.Object, 0, len(routes.Items)+len(grants.Items))
+	for i := range routes.Items {
+		objects = append(objects, &routes.Items[i])
+	}
+	for i := range grants.Items {
+		objects = append(objects, &grants.Items[i])
+	}
+
+	var deleted []client.Object
+	for _, obj := range objects {
+		if !r.ownsGenerated(obj) || (keep != nil && keep(obj)) {
+			continue
+		}
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted = append(deleted, obj)
+	}
+	return deleted, nil
+}
+
+// syncExposedRouteStatus mirrors the route conditions reported for target
+// onto es and publishes the URL of its first hostname once accepted.
+func (r *ServiceReconciler) syncExposedRouteStatus(
+	ctx context.Context, es *httproutev1alpha1.ExposedService, target gatewayTarget,
+) error {
+	route := &gatewayv1.HTTPRoute{}
+	key := types.NamespacedName{Name: exposedRouteName(es.Namespace, es.Name), Namespace: target.Namespace}
+	if err := r.Get(ctx, key, route); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	var parentConditions []metav1.Condition
+	if parent := findRouteParent(route, target); parent != nil {
+		parentConditions = parent.Conditions
+	}
+	for _, mirror := range routeConditions {
+		cond := meta.FindStatusCondition(parentConditions, string(mirror.route))
+		if cond == nil {
+			r.setExposedCondition(es, mirror.service, metav1.ConditionUnknown, ReasonPending,
+				"Waiting for the gateway controller to report HTTPRoute status")
+			continue
+		}
+		r.setExposedCondition(es, mirror.service, cond.Status, cond.Reason, cond.Message)
+	}
+
+	es.Status.URL = ""
+	if !meta.IsStatusConditionTrue(es.Status.Conditions, ConditionHTTPRouteAccepted) {
+		return nil
+	}
+	gw := &gatewayv1.Gateway{}
+	if err := r.Get(ctx, types.NamespacedName{Name: target.Name, Namespace: target.Namespace}, gw); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	for _, listener := range gw.Spec.Listeners {
+		if string(listener.Name) == target.SectionName {
+			es.Status.URL = endpointURL(listener, string(es.Spec.Hostnames[0]))
+		}
+	}
+	return nil
+}
+
+// setExposedCondition sets a condition on the ExposedService status.
+func (r *ServiceReconciler) setExposedCondition(
+	es *httproutev1alpha1.ExposedService, conditionType string, status metav1.ConditionStatus, reason, message string,
+) {
+	meta.SetStatusCondition(&es.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: es.Generation,
+	})
+}
+
+// requestForExposedResource maps a generated HTTPRoute to its ExposedService.
+func (r *ServiceReconciler) requestForExposedResource(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	if labels[LabelManagedBy] != ManagedByValue || labels[LabelExposedServiceName] == "" || !r.ownsGenerated(obj) {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: labels[LabelExposedServiceNamespace],
+		Name:      labels[LabelExposedServiceName],
+	}}}
+}
+
+// requestsForServiceExposures requeues the ExposedServices pointing at a changed Service.
+func (r *ServiceReconciler) requestsForServiceExposures(ctx context.Context, obj client.Object) []reconcile.Request {
+	exposures := &httproutev1alpha1.ExposedServiceList{}
+	if err := r.List(ctx, exposures, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range exposures.Items {
+		if exposures.Items[i].Spec.ServiceName == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&exposures.Items[i])})
+		}
+	}
+	return requests
+}
+
+// requestsForGatewayExposures requeues the ExposedServices attached to a changed Gateway.
+func (r *ServiceReconciler) requestsForGatewayExposures(ctx context.Context, gw client.Object) []reconcile.Request {
+	exposures := &httproutev1alpha1.ExposedServiceList{}
+	if err := r.List(ctx, exposures); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range exposures.Items {
+		for _, target := range r.exposedGatewayTargets(&exposures.Items[i]) {
+			if target.Name == gw.GetName() && target.Namespace == gw.GetNamespace() {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&exposures.Items[i])})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// requestsForAllExposures requeues every ExposedService after a policy change.
+func (r *ServiceReconciler) requestsForAllExposures(ctx context.Context, _ client.Object) []reconcile.Request {
+	exposures := &httproutev1alpha1.ExposedServiceList{}
+	if err := r.List(ctx, exposures); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(exposures.Items))
+	for i := range exposures.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&exposures.Items[i])})
+	}
+	return requests
+}
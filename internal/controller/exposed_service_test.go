@@ -0,0 +1,319 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("ExposedService", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When building a route without rules", func() {
+		It("should send every request to the Service port", func() {
+			route := newHTTPRoute(routeParams{
+				Name:      "route",
+				Namespace: "gateways",
+				Parents: []gatewayTarget{
+					{Name: "eg", Namespace: "gateways", SectionName: "https"},
+					{Name: "eg", Namespace: "gateways"},
+				},
+				Hostnames: []gatewayv1.Hostname{"a.example.com"},
+				Service:   types.NamespacedName{Name: "api", Namespace: "apps"},
+				Port:      8080,
+			})
+
+			Expect(route.Spec.ParentRefs).To(HaveLen(2))
+			Expect(string(*route.Spec.ParentRefs[0].SectionName)).To(Equal("https"))
+			Expect(route.Spec.ParentRefs[1].SectionName).To(BeNil())
+			Expect(route.Spec.Rules).To(HaveLen(1))
+			Expect(route.Spec.Rules[0].Matches).To(BeEmpty())
+			Expect(string(route.Spec.Rules[0].BackendRefs[0].Name)).To(Equal("api"))
+			Expect(int32(*route.Spec.Rules[0].BackendRefs[0].Port)).To(Equal(int32(8080)))
+		})
+	})
+
+	Context("When an ExposedService describes matches and filters", func() {
+		It("should generate the route and report it in the status", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service without annotations
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-exposed",
+					Namespace: "default",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{
+						Name:       "http",
+						Port:       8080,
+						TargetPort: intstr.FromInt(8080),
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ACT: Create the ExposedService
+			pathType := gatewayv1.PathMatchPathPrefix
+			path := "/v1"
+			es := &httproutev1alpha1.ExposedService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-exposed",
+					Namespace: "default",
+				},
+				Spec: httproutev1alpha1.ExposedServiceSpec{
+					ServiceName: "test-svc-exposed",
+					Hostnames:   []gatewayv1.Hostname{"exposed.example.com"},
+					Rules: []httproutev1alpha1.ExposedServiceRule{{
+						Matches: []gatewayv1.HTTPRouteMatch{{
+							Path: &gatewayv1.HTTPPathMatch{Type: &pathType, Value: &path},
+						}},
+						Filters: []gatewayv1.HTTPRouteFilter{{
+							Type: gatewayv1.HTTPRouteFilterRequestHeaderModifier,
+							RequestHeaderModifier: &gatewayv1.HTTPHeaderFilter{
+								Set: []gatewayv1.HTTPHeader{{Name: "X-Api-Version", Value: "v1"}},
+							},
+						}},
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+
+			// ASSERT: HTTPRoute carries the typed rule and the default listener
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      exposedRouteName("default", "test-exposed"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("exposed.example.com")))
+			Expect(string(*route.Spec.ParentRefs[0].SectionName)).To(Equal("https"))
+			Expect(route.Spec.Rules).To(HaveLen(1))
+			Expect(*route.Spec.Rules[0].Matches[0].Path.Value).To(Equal("/v1"))
+			Expect(route.Spec.Rules[0].Filters[0].RequestHeaderModifier.Set[0].Name).
+				To(Equal(gatewayv1.HTTPHeaderName("X-Api-Version")))
+			Expect(int32(*route.Spec.Rules[0].BackendRefs[0].Port)).To(Equal(int32(8080)))
+			Expect(route.Labels).To(HaveKeyWithValue(LabelExposedServiceName, "test-exposed"))
+			Expect(route.Labels).NotTo(HaveKey(LabelServiceName))
+
+			// ASSERT: ReferenceGrant is owned by the ExposedService
+			grant := &gatewayv1beta1.ReferenceGrant{}
+			grantKey := types.NamespacedName{
+				Name:      exposedReferenceGrantName("default", "test-exposed"),
+				Namespace: "default",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, grantKey, grant)
+			}, timeout, interval).Should(Succeed())
+			Expect(grant.OwnerReferences).To(ContainElement(HaveField("Kind", "ExposedService")))
+
+			// ASSERT: Status reports the route as generated
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(es), es); err != nil {
+					return false
+				}
+				return meta.IsStatusConditionTrue(es.Status.Conditions, ConditionExposed)
+			}, timeout, interval).Should(BeTrue())
+
+			// ACT: Delete the ExposedService
+			Expect(k8sClient.Delete(ctx, es)).Should(Succeed())
+
+			// ASSERT: HTTPRoute is removed with it
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When the Service of an ExposedService does not exist", func() {
+		It("should report it without generating a route", func() {
+			ctx := context.Background()
+
+			// ACT: Create an ExposedService for a missing Service
+			es := &httproutev1alpha1.ExposedService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-exposed-missing",
+					Namespace: "default",
+				},
+				Spec: httproutev1alpha1.ExposedServiceSpec{
+					ServiceName: "does-not-exist",
+					Hostnames:   []gatewayv1.Hostname{"missing.example.com"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, es) }()
+
+			// ASSERT: Exposed is False with ServiceNotFound
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(es), es); err != nil {
+					return ""
+				}
+				if cond := meta.FindStatusCondition(es.Status.Conditions, ConditionExposed); cond != nil {
+					return cond.Reason
+				}
+				return ""
+			}, timeout, interval).Should(Equal(ReasonServiceNotFound))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      exposedRouteName("default", "test-exposed-missing"),
+				Namespace: "envoy-gateway-system",
+			}, &gatewayv1.HTTPRoute{}))).To(BeTrue())
+		})
+	})
+
+	Context("When an ExposedService claims the hostname of an exposed Service", func() {
+		It("should leave the hostname to the earlier claimant", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service exposed on shared.example.com
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-shared-hostname",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "shared.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      routeName("default", "test-svc-shared-hostname"),
+					Namespace: "envoy-gateway-system",
+				}, &gatewayv1.HTTPRoute{})
+			}, timeout, interval).Should(Succeed())
+
+			// ACT: Create an ExposedService for the same hostname
+			es := &httproutev1alpha1.ExposedService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-exposed-shared-hostname",
+					Namespace: "default",
+				},
+				Spec: httproutev1alpha1.ExposedServiceSpec{
+					ServiceName: "test-svc-shared-hostname",
+					Hostnames:   []gatewayv1.Hostname{"Shared.example.com"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, es) }()
+
+			// ASSERT: Exposed is False with HostnameConflict and no route is generated
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(es), es); err != nil {
+					return ""
+				}
+				if cond := meta.FindStatusCondition(es.Status.Conditions, ConditionExposed); cond != nil {
+					return cond.Reason
+				}
+				return ""
+			}, timeout, interval).Should(Equal(ReasonHostnameConflict))
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      exposedRouteName("default", "test-exposed-shared-hostname"),
+				Namespace: "envoy-gateway-system",
+			}, &gatewayv1.HTTPRoute{}))).To(BeTrue())
+		})
+	})
+
+	Context("When comparing the paths two claimants route", func() {
+		It("should only report a conflict for paths both can match", func() {
+			api := pathClaim{path: "/api"}
+			Expect(pathClaimsOverlap([]pathClaim{api}, []pathClaim{{path: "/web"}})).To(BeFalse())
+			Expect(pathClaimsOverlap([]pathClaim{api}, []pathClaim{{path: "/apiv2"}})).To(BeFalse())
+			Expect(pathClaimsOverlap([]pathClaim{api}, []pathClaim{{path: "/api/v1"}})).To(BeTrue())
+			Expect(pathClaimsOverlap([]pathClaim{api}, []pathClaim{{exact: true, path: "/api/v1"}})).To(BeTrue())
+			Expect(pathClaimsOverlap([]pathClaim{{exact: true, path: "/api"}}, []pathClaim{{exact: true, path: "/web"}})).
+				To(BeFalse())
+			Expect(pathClaimsOverlap(nil, []pathClaim{api})).To(BeTrue())
+
+			prefix := gatewayv1.PathMatchPathPrefix
+			regex := gatewayv1.PathMatchRegularExpression
+			root, trailing := "/", "/api/"
+			Expect(normalizePathMatch(&gatewayv1.HTTPPathMatch{Type: &prefix, Value: &trailing})).To(Equal(api))
+			_, ok := normalizePathMatch(&gatewayv1.HTTPPathMatch{Type: &prefix, Value: &root})
+			Expect(ok).To(BeFalse())
+			_, ok = normalizePathMatch(&gatewayv1.HTTPPathMatch{Type: &regex, Value: &trailing})
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("When two ExposedServices split a hostname by path", func() {
+		It("should route both", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Backend Service
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-split-hostname",
+					Namespace: "default",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ACT: Create ExposedServices for /api and /web on the same hostname
+			pathType := gatewayv1.PathMatchPathPrefix
+			for _, path := range []string{"/api", "/web"} {
+				es := &httproutev1alpha1.ExposedService{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-exposed-split" + strings.ReplaceAll(path, "/", "-"),
+						Namespace: "default",
+					},
+					Spec: httproutev1alpha1.ExposedServiceSpec{
+						ServiceName: "test-svc-split-hostname",
+						Hostnames:   []gatewayv1.Hostname{"split.example.com"},
+						Rules: []httproutev1alpha1.ExposedServiceRule{{
+							Matches: []gatewayv1.HTTPRouteMatch{{
+								Path: &gatewayv1.HTTPPathMatch{Type: &pathType, Value: &path},
+							}},
+						}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+				defer func() { _ = k8sClient.Delete(ctx, es) }()
+			}
+
+			// ASSERT: Both ExposedServices get their route
+			for _, name := range []string{"test-exposed-split-api", "test-exposed-split-web"} {
+				Eventually(func() error {
+					return k8sClient.Get(ctx, types.NamespacedName{
+						Name:      exposedRouteName("default", name),
+						Namespace: "envoy-gateway-system",
+					}, &gatewayv1.HTTPRoute{})
+				}, timeout, interval).Should(Succeed())
+			}
+		})
+	})
+})
@@ -0,0 +1,106 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+const (
+	// AnnotationExposureClass selects the ExposureClass providing gateway,
+	// hostname template and route filter defaults for a Service
+	AnnotationExposureClass = AnnotationPrefix + "/exposure-class"
+	// ReasonExposureClassNotFound means the selected ExposureClass does not exist
+	ReasonExposureClassNotFound = "ExposureClassNotFound"
+)
+
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposureclasses,verbs=get;list;watch
+
+// exposureClass returns the ExposureClass name, or nil when it does not exist.
+func (r *ServiceReconciler) exposureClass(ctx context.Context, name string) (*httproutev1alpha1.ExposureClass, error) {
+	class := &httproutev1alpha1.ExposureClass{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, class); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return class, nil
+}
+
+// classSettings returns the gateway settings of class keyed by the equivalent Service annotation.
+func classSettings(class *httproutev1alpha1.ExposureClass) map[string]string {
+	if class == nil {
+		return nil
+	}
+	return map[string]string{
+		AnnotationGateway:          class.Spec.Gateway,
+		AnnotationGatewayNamespace: class.Spec.GatewayNamespace,
+		AnnotationSectionName:      class.Spec.SectionName,
+	}
+}
+
+// classRules returns the route rule carrying the filters and timeouts of
+// class, or nil when it sets none.
+func classRules(class *httproutev1alpha1.ExposureClass) []httproutev1alpha1.ExposedServiceRule {
+	if class == nil {
+		return nil
+	}
+	filters := class.Spec.Filters
+	if auth := class.Spec.AuthFilter; auth != nil {
+		filters = append(append([]gatewayv1.HTTPRouteFilter{}, filters...), gatewayv1.HTTPRouteFilter{
+			Type:         gatewayv1.HTTPRouteFilterExtensionRef,
+			ExtensionRef: auth.DeepCopy(),
+		})
+	}
+	if len(filters) == 0 && class.Spec.Timeouts == nil {
+		return nil
+	}
+	return []httproutev1alpha1.ExposedServiceRule{{Filters: filters, Timeouts: class.Spec.Timeouts}}
+}
+
+// checkExposureClass returns a message when svc selects an ExposureClass that
+// does not exist. Routing with partial defaults could expose it on the wrong Gateway.
+func (r *ServiceReconciler) checkExposureClass(ctx context.Context, svc *corev1.Service) (string, error) {
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return "", err
+	}
+	if s.className == "" || s.class != nil {
+		return "", nil
+	}
+	return fmt.Sprintf("ExposureClass %s not found", s.className), nil
+}
+
+// requestsForClassGateway requeues the exposed Services when an ExposureClass
+// targets gw. The gateway index only covers Service annotations.
+func (r *ServiceReconciler) requestsForClassGateway(ctx context.Context, gw client.Object) []reconcile.Request {
+	classes := &httproutev1alpha1.ExposureClassList{}
+	if err := r.List(ctx, classes); err != nil {
+		return nil
+	}
+	for _, class := range classes.Items {
+		if class.Spec.Gateway == "" && class.Spec.GatewayNamespace == "" {
+			continue
+		}
+		if valueOrDefault(class.Spec.GatewayNamespace, r.config().DefaultGatewayNamespace) == gw.GetNamespace() &&
+			(class.Spec.Gateway == "" || class.Spec.Gateway == gw.GetName()) {
+			return r.requestsForExposedServices(ctx, gw)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("ExposureClass", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When a class sets filters and an auth filter", func() {
+		It("should add them after the class filters in a single rule", func() {
+			class := &httproutev1alpha1.ExposureClass{Spec: httproutev1alpha1.ExposureClassSpec{
+				Filters: []gatewayv1.HTTPRouteFilter{{Type: gatewayv1.HTTPRouteFilterResponseHeaderModifier}},
+				AuthFilter: &gatewayv1.LocalObjectReference{
+					Group: "gateway.envoyproxy.io", Kind: "HTTPRouteFilter", Name: "oidc",
+				},
+			}}
+
+			rules := classRules(class)
+
+			Expect(rules).To(HaveLen(1))
+			Expect(rules[0].Filters).To(HaveLen(2))
+			Expect(rules[0].Filters[1].Type).To(Equal(gatewayv1.HTTPRouteFilterExtensionRef))
+			Expect(string(rules[0].Filters[1].ExtensionRef.Name)).To(Equal("oidc"))
+			Expect(class.Spec.Filters).To(HaveLen(1))
+			Expect(classRules(&httproutev1alpha1.ExposureClass{})).To(BeNil())
+		})
+	})
+
+	Context("When a Service selects an ExposureClass", func() {
+		It("should use the class listener, hostname template and filters", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Class with its own listener, template and timeout
+			request := gatewayv1.Duration("30s")
+			class := &httproutev1alpha1.ExposureClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-public-web"},
+				Spec: httproutev1alpha1.ExposureClassSpec{
+					GatewayNamespace: "custom-ns",
+					SectionName:      "public",
+					HostnameTemplate: "{{.Name}}.public.example.com",
+					Timeouts:         &gatewayv1.HTTPRouteTimeouts{Request: &request},
+				},
+			}
+			Expect(k8sClient.Create(ctx, class)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, class) }()
+
+			// ACT: Create a Service selecting the class
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-class",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":         "true",
+						"httproute.controller/exposure-class": "test-public-web",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{
+						Name:       "http",
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: HTTPRoute follows the class
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-class"),
+				Namespace: "custom-ns",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("test-svc-class.public.example.com")))
+			Expect(string(*route.Spec.ParentRefs[0].SectionName)).To(Equal("public"))
+			Expect(route.Spec.Rules[0].Timeouts).NotTo(BeNil())
+			Expect(*route.Spec.Rules[0].Timeouts.Request).To(Equal(request))
+		})
+	})
+
+	Context("When a Service selects a missing ExposureClass", func() {
+		It("should not expose it", func() {
+			ctx := context.Background()
+
+			// ACT: Create a Service selecting an unknown class
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-class-missing",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":         "true",
+						"httproute.controller/hostname":       "missing-class.example.com",
+						"httproute.controller/exposure-class": "does-not-exist",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{
+						Name:       "http",
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: Exposed is False with ExposureClassNotFound
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc); err != nil {
+					return ""
+				}
+				if cond := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed); cond != nil {
+					return cond.Reason
+				}
+				return ""
+			}, timeout, interval).Should(Equal(ReasonExposureClassNotFound))
+		})
+	})
+})
@@ -0,0 +1,257 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+const (
+	// ReasonExposureDenied means an ExposurePolicy rule denied the route or could not be evaluated
+	ReasonExposureDenied = "ExposureDenied"
+	// ReasonCompiled means every rule of an ExposurePolicy compiles
+	ReasonCompiled = "Compiled"
+	// ReasonInvalidExpression means a rule of an ExposurePolicy does not compile
+	ReasonInvalidExpression = "InvalidExpression"
+)
+
+// policyCostLimit bounds the evaluation cost of a single policy expression
+const policyCostLimit = 1000000
+
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposurepolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposurepolicies/status,verbs=get;update;patch
+
+// policyRoute is the resolved route ExposurePolicy rules see as the variable route
+type policyRoute struct {
+	Hostnames        []string
+	Gateway          string
+	GatewayNamespace string
+	SectionName      string
+	Port             int32
+}
+
+// policyEnv declares the variables available to ExposurePolicy expressions.
+var policyEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("service", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("namespaceObject", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("route", cel.MapType(cel.StringType, cel.DynType)),
+	)
+})
+
+// compiledPolicy holds the programs of one ExposurePolicy generation, or why
+// it does not compile.
+type compiledPolicy struct {
+	generation int64
+	programs   []cel.Program
+	err        error
+}
+
+// policyPrograms caches compiled ExposurePolicies by UID, so rules are
+// compiled once per generation instead of for every route.
+var policyPrograms = struct {
+	sync.Mutex
+	policies map[types.UID]compiledPolicy
+}{policies: map[types.UID]compiledPolicy{}}
+
+// compilePolicy returns the programs of the rules of policy in order.
+func compilePolicy(policy *httproutev1alpha1.ExposurePolicy) ([]cel.Program, error) {
+	policyPrograms.Lock()
+	defer policyPrograms.Unlock()
+	if compiled, ok := policyPrograms.policies[policy.UID]; ok && compiled.generation == policy.Generation {
+		return compiled.programs, compiled.err
+	}
+	compiled := compiledPolicy{generation: policy.Generation}
+	for i, rule := range policy.Spec.Rules {
+		program, err := compilePolicyRule(rule.Expression)
+		if err != nil {
+			compiled.programs, compiled.err = nil, fmt.Errorf("rule %d: %w", i, err)
+			break
+		}
+		compiled.programs = append(compiled.programs, program)
+	}
+	policyPrograms.policies[policy.UID] = compiled
+	return compiled.programs, compiled.err
+}
+
+// forgetPolicies drops the compiled policies whose UID is not in policies.
+func forgetPolicies(policies []httproutev1alpha1.ExposurePolicy) {
+	uids := make(map[types.UID]bool, len(policies))
+	for _, policy := range policies {
+		uids[policy.UID] = true
+	}
+	policyPrograms.Lock()
+	defer policyPrograms.Unlock()
+	for uid := range policyPrograms.policies {
+		if !uids[uid] {
+			delete(policyPrograms.policies, uid)
+		}
+	}
+}
+
+// checkExposurePolicies returns a message describing why the ExposurePolicies
+// selecting the namespace of svc deny route, or an empty string when every
+// rule allows it. Policies that do not compile and rules that cannot be
+// evaluated deny the route.
+func (r *ServiceReconciler) checkExposurePolicies(
+	ctx context.Context, svc *corev1.Service, route policyRoute,
+) (string, error) {
+	policies := &httproutev1alpha1.ExposurePolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		return "", err
+	}
+	if len(policies.Items) == 0 {
+		return "", nil
+	}
+	ns, err := r.serviceNamespace(ctx, svc)
+	if err != nil {
+		return "", err
+	}
+	vars, err := policyVariables(svc, ns, route)
+	if err != nil {
+		return "", err
+	}
+
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if policy.Spec.NamespaceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+			if err != nil {
+				return "", fmt.Errorf("ExposurePolicy %s has an invalid namespaceSelector: %w", policy.Name, err)
+			}
+			if !selector.Matches(labels.Set(ns.Labels)) {
+				continue
+			}
+		}
+		programs, err := compilePolicy(policy)
+		if err != nil {
+			return fmt.Sprintf("ExposurePolicy %s is invalid: %v", policy.Name, err), nil
+		}
+		for i, program := range programs {
+			allowed, err := evalPolicyProgram(program, vars)
+			if err != nil {
+				return fmt.Sprintf("ExposurePolicy %s rule %d cannot be evaluated: %v", policy.Name, i, err), nil
+			}
+			if !allowed {
+				rule := policy.Spec.Rules[i]
+				return fmt.Sprintf("denied by ExposurePolicy %s: %s",
+					policy.Name, valueOrDefault(rule.Message, rule.Expression)), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// policyVariables returns the CEL activation of an ExposurePolicy evaluation.
+func policyVariables(svc *corev1.Service, ns *corev1.Namespace, route policyRoute) (map[string]any, error) {
+	service, err := runtime.DefaultUnstructuredConverter.ToUnstructured(svc)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ns)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"service":         service,
+		"namespaceObject": namespace,
+		"route": map[string]any{
+			"hostnames":        route.Hostnames,
+			"gateway":          route.Gateway,
+			"gatewayNamespace": route.GatewayNamespace,
+			"sectionName":      route.SectionName,
+			"port":             int64(route.Port),
+		},
+	}, nil
+}
+
+// compilePolicyRule compiles expression, rejecting expressions whose type is
+// known not to be bool.
+func compilePolicyRule(expression string) (cel.Program, error) {
+	env, err := policyEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if out := ast.OutputType(); !out.IsExactType(cel.BoolType) && !out.IsExactType(cel.DynType) {
+		return nil, fmt.Errorf("expression returns %s, not bool", out)
+	}
+	return env.Program(ast, cel.CostLimit(policyCostLimit))
+}
+
+// evalPolicyProgram evaluates a compiled rule against vars.
+func evalPolicyProgram(program cel.Program, vars map[string]any) (bool, error) {
+	out, _, err := program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	allowed, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %s, not bool", out.Type().TypeName())
+	}
+	return allowed, nil
+}
+
+// reconcileExposurePolicy reports in the Valid condition of an ExposurePolicy
+// whether its namespaceSelector is valid and its rules compile.
+func (r *ServiceReconciler) reconcileExposurePolicy(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	policy := &httproutev1alpha1.ExposurePolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		policies := &httproutev1alpha1.ExposurePolicyList{}
+		if err := r.List(ctx, policies); err != nil {
+			return ctrl.Result{}, err
+		}
+		forgetPolicies(policies.Items)
+		return ctrl.Result{}, nil
+	}
+	original := policy.DeepCopy()
+
+	valid := metav1.Condition{
+		Type:               ConditionValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonCompiled,
+		Message:            "All rules compile",
+		ObservedGeneration: policy.Generation,
+	}
+	if policy.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector); err != nil {
+			valid.Status, valid.Reason, valid.Message = metav1.ConditionFalse, ReasonInvalidSelector, err.Error()
+		}
+	}
+	if _, err := compilePolicy(policy); err != nil {
+		valid.Status, valid.Reason, valid.Message = metav1.ConditionFalse, ReasonInvalidExpression, err.Error()
+	}
+	meta.SetStatusCondition(&policy.Status.Conditions, valid)
+	policy.Status.ObservedGeneration = policy.Generation
+
+	if equality.Semantic.DeepEqual(original.Status, policy.Status) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, r.Status().Patch(ctx, policy, client.MergeFrom(original))
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("ExposurePolicy", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When evaluating policy expressions", func() {
+		It("should see the Service, Namespace and route", func() {
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name:   "postgres",
+				Labels: map[string]string{"tier": "db"},
+			}}
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-payments"}}
+			vars, err := policyVariables(svc, ns, policyRoute{Hostnames: []string{"pg.example.com"}, Port: 5432})
+			Expect(err).NotTo(HaveOccurred())
+
+			evaluate := func(expression string) (bool, error) {
+				program, err := compilePolicyRule(expression)
+				if err != nil {
+					return false, err
+				}
+				return evalPolicyProgram(program, vars)
+			}
+
+			Expect(evaluate("service.metadata.labels.tier != 'db'")).To(BeFalse())
+			Expect(evaluate("route.port >= 1024")).To(BeTrue())
+			Expect(evaluate(
+				"!namespaceObject.metadata.name.startsWith('prod') || route.hostnames.all(h, h.endsWith('.prod.example.com'))",
+			)).To(BeFalse())
+
+			_, err = evaluate("route.port")
+			Expect(err).To(HaveOccurred())
+			_, err = evaluate("service.metadata.annotations.owner == 'team-a'")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When a policy denies a Service", func() {
+		It("should not create the route and report the denial", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Policy forbidding ports below 1024 in labelled namespaces
+			policy := &httproutev1alpha1.ExposurePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-no-privileged-ports"},
+				Spec: httproutev1alpha1.ExposurePolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"test-policy": "ports"}},
+					Rules: []httproutev1alpha1.ExposurePolicyRule{{
+						Expression: "route.port >= 1024",
+						Message:    "ports below 1024 are forbidden",
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, policy) }()
+
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "test-policy-ns",
+				Labels: map[string]string{"test-policy": "ports"},
+			}}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+			// ACT: Expose a Service on port 80
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-policy",
+					Namespace: "test-policy-ns",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "policy.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{
+						Name:       "http",
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: Exposed is False with the policy message
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc); err != nil {
+					return ""
+				}
+				if cond := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed); cond != nil &&
+					cond.Reason == ReasonExposureDenied {
+					return cond.Message
+				}
+				return ""
+			}, timeout, interval).Should(ContainSubstring("ports below 1024 are forbidden"))
+			routeKey := types.NamespacedName{
+				Name:      routeName("test-policy-ns", "test-svc-policy"),
+				Namespace: "envoy-gateway-system",
+			}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{}))).To(BeTrue())
+
+			// ACT: Move the Service to an allowed port
+			svc.Annotations["httproute.controller/port"] = "8443"
+			svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{
+				Name:       "https",
+				Port:       8443,
+				TargetPort: intstr.FromInt(8443),
+			})
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+
+			// ASSERT: HTTPRoute is created
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{})
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
+	Context("When a policy denies one gateway of an ExposedService", func() {
+		It("should not create the route", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Policy forbidding the internal-only Gateway
+			policy := &httproutev1alpha1.ExposurePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-no-internal-gateway"},
+				Spec: httproutev1alpha1.ExposurePolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"test-policy": "gateways"}},
+					Rules: []httproutev1alpha1.ExposurePolicyRule{{
+						Expression: "route.gateway != 'internal-only'",
+						Message:    "internal-only is not for ExposedServices",
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, policy) }()
+
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "test-policy-gateways-ns",
+				Labels: map[string]string{"test-policy": "gateways"},
+			}}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-svc-policy-gateways", Namespace: ns.Name},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 8080, TargetPort: intstr.FromInt(8080)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ACT: Attach to an allowed Gateway first and the forbidden one second
+			es := &httproutev1alpha1.ExposedService{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-exposed-policy-gateways", Namespace: ns.Name},
+				Spec: httproutev1alpha1.ExposedServiceSpec{
+					ServiceName: svc.Name,
+					Hostnames:   []gatewayv1.Hostname{"policy-gateways.example.com"},
+					GatewayRefs: []httproutev1alpha1.GatewayReference{{Name: "test-gateway"}, {Name: "internal-only"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, es)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, es) }()
+
+			// ASSERT: Exposed is False with the policy message
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(es), es); err != nil {
+					return ""
+				}
+				if cond := meta.FindStatusCondition(es.Status.Conditions, ConditionExposed); cond != nil &&
+					cond.Reason == ReasonExposureDenied {
+					return cond.Message
+				}
+				return ""
+			}, timeout, interval).Should(ContainSubstring("internal-only is not for ExposedServices"))
+		})
+	})
+
+	Context("When a policy rule does not compile", func() {
+		It("should report InvalidExpression and deny routes", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Policy with a rule returning a string
+			policy := &httproutev1alpha1.ExposurePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-invalid-expression"},
+				Spec: httproutev1alpha1.ExposurePolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"test-policy": "invalid"}},
+					Rules: []httproutev1alpha1.ExposurePolicyRule{
+						{Expression: "route.port >= 1024"},
+						{Expression: "'not a bool'"},
+					},
+				},
+			}
+
+			// ACT: Create the policy
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, policy) }()
+
+			// ASSERT: Valid is False with InvalidExpression naming the rule
+			Eventually(func() *metav1.Condition {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(policy), policy); err != nil {
+					return nil
+				}
+				return meta.FindStatusCondition(policy.Status.Conditions, ConditionValid)
+			}, timeout, interval).Should(And(
+				Not(BeNil()),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", ReasonInvalidExpression),
+				HaveField("Message", ContainSubstring("rule 1")),
+			))
+
+			// ASSERT: The compiled policy denies routes
+			programs, err := compilePolicy(policy)
+			Expect(err).To(MatchError(ContainSubstring("rule 1")))
+			Expect(programs).To(BeEmpty())
+
+			// ACT: Fix the rule
+			policy.Spec.Rules[1].Expression = "route.hostnames.all(h, h != '')"
+			Expect(k8sClient.Update(ctx, policy)).Should(Succeed())
+
+			// ASSERT: The new generation is compiled and Valid
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(policy), policy); err != nil {
+					return false
+				}
+				return meta.IsStatusConditionTrue(policy.Status.Conditions, ConditionValid)
+			}, timeout, interval).Should(BeTrue())
+			programs, err = compilePolicy(policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(programs).To(HaveLen(2))
+		})
+	})
+})
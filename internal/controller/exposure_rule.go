@@ -0,0 +1,255 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+const (
+	// AnnotationExposureRule records the ExposureRule applying to a Service, so
+	// watches and indexes can tell it is exposed without listing rules
+	AnnotationExposureRule = AnnotationPrefix + "/exposure-rule"
+	// ReasonInvalidSelector means an ExposureRule has a selector that cannot be parsed
+	ReasonInvalidSelector = "InvalidSelector"
+)
+
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposurerules,verbs=get;list;watch
+// +kubebuilder:rbac:groups=httproute.controller,resources=exposurerules/status,verbs=get;update;patch
+
+// exposureRuleFor returns the ExposureRule applying to svc, or nil.
+func (r *ServiceReconciler) exposureRuleFor(
+	ctx context.Context, svc *corev1.Service,
+) (*httproutev1alpha1.ExposureRule, error) {
+	rules := &httproutev1alpha1.ExposureRuleList{}
+	if err := r.List(ctx, rules, client.InNamespace(svc.Namespace)); err != nil {
+		return nil, err
+	}
+	return selectExposureRule(rules.Items, svc), nil
+}
+
+// selectExposureRule returns the rule applying to svc: rules naming the
+// Service win over selectors, then the rule created first.
+func selectExposureRule(rules []httproutev1alpha1.ExposureRule, svc *corev1.Service) *httproutev1alpha1.ExposureRule {
+	var selected *httproutev1alpha1.ExposureRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.DeletionTimestamp.IsZero() || !ruleMatches(rule, svc) {
+			continue
+		}
+		if selected == nil || ruleBefore(rule, selected) {
+			selected = rule
+		}
+	}
+	return selected
+}
+
+// ruleMatches reports whether rule selects svc. Rules with an invalid
+// selector match nothing and report it in their status.
+func ruleMatches(rule *httproutev1alpha1.ExposureRule, svc *corev1.Service) bool {
+	if rule.Spec.ServiceName != "" {
+		return rule.Spec.ServiceName == svc.Name
+	}
+	if rule.Spec.Selector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(rule.Spec.Selector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(svc.Labels))
+}
+
+func ruleBefore(a, b *httproutev1alpha1.ExposureRule) bool {
+	if (a.Spec.ServiceName != "") != (b.Spec.ServiceName != "") {
+		return a.Spec.ServiceName != ""
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// ruleSettings returns the settings of rule keyed by the equivalent Service annotation.
+func ruleSettings(rule *httproutev1alpha1.ExposureRule) map[string]string {
+	if rule == nil {
+		return nil
+	}
+	settings := map[string]string{
+		AnnotationExpose:           "true",
+		AnnotationHostname:         rule.Spec.Hostname,
+		AnnotationGateway:          rule.Spec.Gateway,
+		AnnotationGatewayNamespace: rule.Spec.GatewayNamespace,
+		AnnotationSectionName:      rule.Spec.SectionName,
+		AnnotationExposureClass:    rule.Spec.ExposureClass,
+	}
+	if rule.Spec.Port != 0 {
+		settings[AnnotationPort] = strconv.Itoa(int(rule.Spec.Port))
+	}
+	if rule.Spec.SkipReferenceGrant != nil {
+		settings[AnnotationSkipReferenceGrant] = strconv.FormatBool(*rule.Spec.SkipReferenceGrant)
+	}
+	return settings
+}
+
+// requestsForExposureRule requeues the Services a rule selects now or applied to before.
+func (r *ServiceReconciler) requestsForExposureRule(ctx context.Context, obj client.Object) []reconcile.Request {
+	rule, ok := obj.(*httproutev1alpha1.ExposureRule)
+	if !ok {
+		return nil
+	}
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, client.InNamespace(rule.Namespace)); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if ruleMatches(rule, svc) || r.annotation(svc, AnnotationExposureRule) == rule.Name {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(svc)})
+		}
+	}
+	return requests
+}
+
+// requestsForRuleGateway requeues the Services exposed through ExposureRules
+// targeting gw. The gateway index only covers Service annotations.
+func (r *ServiceReconciler) requestsForRuleGateway(ctx context.Context, gw client.Object) []reconcile.Request {
+	rules := &httproutev1alpha1.ExposureRuleList{}
+	if err := r.List(ctx, rules); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, rule := range rules.Items {
+		if rule.Spec.Gateway == "" && rule.Spec.GatewayNamespace == "" {
+			continue
+		}
+		if valueOrDefault(rule.Spec.GatewayNamespace, r.config().DefaultGatewayNamespace) != gw.GetNamespace() ||
+			(rule.Spec.Gateway != "" && rule.Spec.Gateway != gw.GetName()) {
+			continue
+		}
+		services := &corev1.ServiceList{}
+		if err := r.List(ctx, services, client.InNamespace(rule.Namespace)); err != nil {
+			return nil
+		}
+		for i := range services.Items {
+			if r.annotation(&services.Items[i], AnnotationExposureRule) == rule.Name {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&services.Items[i])})
+			}
+		}
+	}
+	return requests
+}
+
+// requestsForServiceRules requeues the ExposureRules in the namespace of a
+// changed Service, so their status follows the Service.
+func (r *ServiceReconciler) requestsForServiceRules(ctx context.Context, obj client.Object) []reconcile.Request {
+	rules := &httproutev1alpha1.ExposureRuleList{}
+	if err := r.List(ctx, rules, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(rules.Items))
+	for i := range rules.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&rules.Items[i])})
+	}
+	return requests
+}
+
+// handlesResources reports whether this instance reconciles ExposureRule
+// status and ExposedServices, which have no class and are left to the instance
+// reconciling Services without a class.
+func (r *ServiceReconciler) handlesResources() bool {
+	return r.config().ControllerClass == "" || r.config().DefaultClass
+}
+
+// reconcileExposureRule reports the Services an ExposureRule applies to and
+// the state of their routes.
+func (r *ServiceReconciler) reconcileExposureRule(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	rule := &httproutev1alpha1.ExposureRule{}
+	if err := r.Get(ctx, req.NamespacedName, rule); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if managed, err := r.managesNamespace(ctx, rule.Namespace); err != nil || !managed {
+		return ctrl.Result{}, err
+	}
+	original := rule.DeepCopy()
+
+	valid := metav1.Condition{
+		Type:               ConditionValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonApplied,
+		Message:            "Rule applied",
+		ObservedGeneration: rule.Generation,
+	}
+	if rule.Spec.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(rule.Spec.Selector); err != nil {
+			valid.Status, valid.Reason, valid.Message = metav1.ConditionFalse, ReasonInvalidSelector, err.Error()
+		}
+	}
+	meta.SetStatusCondition(&rule.Status.Conditions, valid)
+
+	services, err := r.ruleServices(ctx, rule)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	rule.Status.Services = services
+	rule.Status.ObservedGeneration = rule.Generation
+
+	if equality.Semantic.DeepEqual(original.Status, rule.Status) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, r.Status().Patch(ctx, rule, client.MergeFrom(original))
+}
+
+// ruleServices returns the route state of the Services rule applies to, sorted by name.
+func (r *ServiceReconciler) ruleServices(
+	ctx context.Context, rule *httproutev1alpha1.ExposureRule,
+) ([]httproutev1alpha1.ExposureRuleService, error) {
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, client.InNamespace(rule.Namespace)); err != nil {
+		return nil, err
+	}
+	rules := &httproutev1alpha1.ExposureRuleList{}
+	if err := r.List(ctx, rules, client.InNamespace(rule.Namespace)); err != nil {
+		return nil, err
+	}
+
+	var matched []httproutev1alpha1.ExposureRuleService
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if selected := selectExposureRule(rules.Items, svc); selected == nil || selected.Name != rule.Name {
+			continue
+		}
+		status := httproutev1alpha1.ExposureRuleService{
+			Name:     svc.Name,
+			Hostname: valueOrDefault(r.annotation(svc, AnnotationHostname), r.annotation(svc, AnnotationGeneratedHostname)),
+			URL:      r.annotation(svc, AnnotationURL),
+		}
+		if cond := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed); cond != nil {
+			status.Exposed, status.Reason, status.Message = cond.Status, cond.Reason, cond.Message
+		}
+		matched = append(matched, status)
+	}
+	slices.SortFunc(matched, func(a, b httproutev1alpha1.ExposureRuleService) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return matched, nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("ExposureRule", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When several rules match a Service", func() {
+		It("should prefer rules naming the Service, then the oldest rule", func() {
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name:   "grafana",
+				Labels: map[string]string{"app": "grafana"},
+			}}
+			older := metav1.NewTime(time.Now().Add(-time.Hour))
+			rules := []httproutev1alpha1.ExposureRule{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-label-new", CreationTimestamp: metav1.Now()},
+					Spec: httproutev1alpha1.ExposureRuleSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "by-label-old", CreationTimestamp: older},
+					Spec: httproutev1alpha1.ExposureRuleSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "grafana"}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "other", CreationTimestamp: older},
+					Spec:       httproutev1alpha1.ExposureRuleSpec{ServiceName: "loki"},
+				},
+			}
+
+			Expect(selectExposureRule(rules, svc).Name).To(Equal("by-label-old"))
+
+			rules = append(rules, httproutev1alpha1.ExposureRule{
+				ObjectMeta: metav1.ObjectMeta{Name: "by-name", CreationTimestamp: metav1.Now()},
+				Spec:       httproutev1alpha1.ExposureRuleSpec{ServiceName: "grafana"},
+			})
+			Expect(selectExposureRule(rules, svc).Name).To(Equal("by-name"))
+		})
+	})
+
+	Context("When an ExposureRule selects a Service without annotations", func() {
+		It("should expose it with the rule settings and report it in the rule status", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service with two ports and no annotations
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-rule",
+					Namespace: "default",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Name:       "http",
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+						{
+							Name:       "admin",
+							Port:       9090,
+							TargetPort: intstr.FromInt(9090),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ACT: Create the rule
+			rule := &httproutev1alpha1.ExposureRule{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-rule",
+					Namespace: "default",
+				},
+				Spec: httproutev1alpha1.ExposureRuleSpec{
+					ServiceName: "test-svc-rule",
+					Hostname:    "rule.example.com",
+					Port:        9090,
+				},
+			}
+			Expect(k8sClient.Create(ctx, rule)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, rule) }()
+
+			// ASSERT: HTTPRoute uses the rule hostname and port
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-rule"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("rule.example.com")))
+			Expect(int32(*route.Spec.Rules[0].BackendRefs[0].Port)).To(Equal(int32(9090)))
+
+			// ASSERT: Service records the rule and the rule lists the exposed Service
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			Expect(svc.Annotations).To(HaveKeyWithValue(AnnotationExposureRule, "test-rule"))
+			Eventually(func() []httproutev1alpha1.ExposureRuleService {
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(rule), rule); err != nil {
+					return nil
+				}
+				return rule.Status.Services
+			}, timeout, interval).Should(ContainElement(And(
+				HaveField("Name", "test-svc-rule"),
+				HaveField("Hostname", "rule.example.com"),
+				HaveField("Exposed", metav1.ConditionTrue),
+			)))
+
+			// ACT: Annotate a different hostname on the Service
+			svc.Annotations["httproute.controller/hostname"] = "annotated.example.com"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+
+			// ASSERT: Service annotation wins over the rule
+			Eventually(func() []gatewayv1.Hostname {
+				if err := k8sClient.Get(ctx, routeKey, route); err != nil {
+					return nil
+				}
+				return route.Spec.Hostnames
+			}, timeout, interval).Should(ConsistOf(gatewayv1.Hostname("annotated.example.com")))
+		})
+	})
+})
@@ -0,0 +1,195 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// exactHostnameScore ranks an exact listener hostname above any wildcard
+const exactHostnameScore = 1 << 16
+
+// gatewayTarget identifies a single Gateway listener an HTTPRoute attaches to
+type gatewayTarget struct {
+	Name        string
+	Namespace   string
+	SectionName string
+}
+
+// listenerCandidate is a listener that accepts the route, ranked by score
+type listenerCandidate struct {
+	target    gatewayTarget
+	score     int
+	isDefault bool
+	isHTTPS   bool
+}
+
+// better reports whether c should be preferred over other. Ties on hostname
+// specificity go to the configured default listener, then to HTTPS.
+func (c listenerCandidate) better(other listenerCandidate) bool {
+	if c.score != other.score {
+		return c.score > other.score
+	}
+	if c.isDefault != other.isDefault {
+		return c.isDefault
+	}
+	return c.isHTTPS && !other.isHTTPS
+}
+
+// candidateGatewayNamespaces returns the namespaces whose Gateways may be used
+// for the Service: the annotated gateway namespace, or the default gateway
+// namespace plus any additionally allowed ones.
+func (r *ServiceReconciler) candidateGatewayNamespaces(gatewayNamespace string) []string {
+	if gatewayNamespace != "" {
+		return []string{gatewayNamespace}
+	}
+	namespaces := []string{r.config().DefaultGatewayNamespace}
+	for _, ns := range r.config().GatewayNamespaces {
+		if !slices.Contains(namespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
+
+// selectListener picks the listener whose hostname best matches hostname and
+// whose allowedRoutes admit an HTTPRoute in the Gateway namespace. It returns
+// false when no listener matches.
+func (r *ServiceReconciler) selectListener(
+	ctx context.Context, gatewayNamespace, hostname string,
+) (gatewayTarget, bool, error) {
+	var best *listenerCandidate
+
+	for _, ns := range r.candidateGatewayNamespaces(gatewayNamespace) {
+		gateways := &gatewayv1.GatewayList{}
+		if err := r.List(ctx, gateways, client.InNamespace(ns)); err != nil {
+			return gatewayTarget{}, false, err
+		}
+
+		for i := range gateways.Items {
+			gw := &gateways.Items[i]
+			for _, listener := range gw.Spec.Listeners {
+				score := hostnameMatchScore(listener.Hostname, hostname)
+				if score < 0 || !listenerSupportsHTTPRoute(listener) {
+					continue
+				}
+				admitted, err := r.listenerAdmitsNamespace(ctx, gw, listener)
+				if err != nil {
+					return gatewayTarget{}, false, err
+				}
+				if !admitted {
+					continue
+				}
+
+				candidate := listenerCandidate{
+					target: gatewayTarget{
+						Name:        gw.Name,
+						Namespace:   gw.Namespace,
+						SectionName: string(listener.Name),
+					},
+					score: score,
+					isDefault: gw.Name == r.config().DefaultGateway &&
+						gw.Namespace == r.config().DefaultGatewayNamespace &&
+						string(listener.Name) == r.config().DefaultSectionName,
+					isHTTPS: listener.Protocol == gatewayv1.HTTPSProtocolType,
+				}
+				if best == nil || candidate.better(*best) {
+					best = &candidate
+				}
+			}
+		}
+	}
+
+	if best == nil {
+		return gatewayTarget{}, false, nil
+	}
+	return best.target, true, nil
+}
+
+// hostnameMatchScore returns how specifically a listener hostname matches the
+// given hostname: -1 for no match, 0 for a listener without hostname, the
+// length of the wildcard suffix for wildcard matches and exactHostnameScore
+// for an exact match.
+func hostnameMatchScore(listenerHostname *gatewayv1.Hostname, hostname string) int {
+	if listenerHostname == nil || *listenerHostname == "" {
+		return 0
+	}
+	pattern := strings.ToLower(string(*listenerHostname))
+	hostname = strings.ToLower(hostname)
+
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		if len(hostname) > len(suffix) && strings.HasSuffix(hostname, suffix) {
+			return len(suffix)
+		}
+		return -1
+	}
+	if pattern == hostname {
+		return exactHostnameScore
+	}
+	return -1
+}
+
+// listenerSupportsHTTPRoute reports whether the listener accepts HTTPRoutes,
+// either explicitly via allowedRoutes.kinds or implicitly via its protocol.
+func listenerSupportsHTTPRoute(listener gatewayv1.Listener) bool {
+	if listener.AllowedRoutes == nil || len(listener.AllowedRoutes.Kinds) == 0 {
+		return listener.Protocol == gatewayv1.HTTPProtocolType ||
+			listener.Protocol == gatewayv1.HTTPSProtocolType
+	}
+	for _, kind := range listener.AllowedRoutes.Kinds {
+		group := gatewayv1.GroupName
+		if kind.Group != nil {
+			group = string(*kind.Group)
+		}
+		if group == gatewayv1.GroupName && kind.Kind == "HTTPRoute" {
+			return true
+		}
+	}
+	return false
+}
+
+// listenerAdmitsNamespace reports whether the listener admits routes from the
+// Gateway namespace, which is where generated HTTPRoutes live.
+func (r *ServiceReconciler) listenerAdmitsNamespace(
+	ctx context.Context, gw *gatewayv1.Gateway, listener gatewayv1.Listener,
+) (bool, error) {
+	if listener.AllowedRoutes == nil || listener.AllowedRoutes.Namespaces == nil ||
+		listener.AllowedRoutes.Namespaces.From == nil {
+		// Gateway API defaults to routes from the same namespace
+		return true, nil
+	}
+
+	switch *listener.AllowedRoutes.Namespaces.From {
+	case gatewayv1.NamespacesFromAll, gatewayv1.NamespacesFromSame:
+		return true, nil
+	case gatewayv1.NamespacesFromSelector:
+		if listener.AllowedRoutes.Namespaces.Selector == nil {
+			return false, nil
+		}
+		selector, err := metav1.LabelSelectorAsSelector(listener.AllowedRoutes.Namespaces.Selector)
+		if err != nil {
+			// An invalid selector admits nothing; the Gateway reports it in its status
+			return false, nil
+		}
+		ns := &corev1.Namespace{}
+		if err := r.Get(ctx, types.NamespacedName{Name: gw.Namespace}, ns); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return selector.Matches(labels.Set(ns.Labels)), nil
+	default:
+		return false, nil
+	}
+}
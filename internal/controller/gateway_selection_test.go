@@ -0,0 +1,163 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Gateway listener selection", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	hostnamePtr := func(h string) *gatewayv1.Hostname {
+		hostname := gatewayv1.Hostname(h)
+		return &hostname
+	}
+
+	Context("When scoring listener hostnames", func() {
+		It("should prefer exact over longer wildcard over shorter wildcard over catch-all", func() {
+			exact := hostnameMatchScore(hostnamePtr("app.internal.example.com"), "app.internal.example.com")
+			internal := hostnameMatchScore(hostnamePtr("*.internal.example.com"), "app.internal.example.com")
+			wildcard := hostnameMatchScore(hostnamePtr("*.example.com"), "app.internal.example.com")
+			catchAll := hostnameMatchScore(nil, "app.internal.example.com")
+
+			Expect(exact).To(BeNumerically(">", internal))
+			Expect(internal).To(BeNumerically(">", wildcard))
+			Expect(wildcard).To(BeNumerically(">", catchAll))
+			Expect(catchAll).To(Equal(0))
+		})
+
+		It("should not match a wildcard against its bare domain or other domains", func() {
+			Expect(hostnameMatchScore(hostnamePtr("*.example.com"), "example.com")).To(Equal(-1))
+			Expect(hostnameMatchScore(hostnamePtr("*.example.com"), "app.example.org")).To(Equal(-1))
+			Expect(hostnameMatchScore(hostnamePtr("app.example.com"), "other.example.com")).To(Equal(-1))
+		})
+	})
+
+	Context("When a Service has no gateway or section annotation", func() {
+		It("should attach to the most specific matching listener", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Gateway with a generic and a more specific wildcard listener
+			gw := &gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "selection-gateway",
+					Namespace: "envoy-gateway-system",
+				},
+				Spec: gatewayv1.GatewaySpec{
+					GatewayClassName: "test-class",
+					Listeners: []gatewayv1.Listener{
+						{
+							Name:     "wildcard",
+							Hostname: hostnamePtr("*.selection.local"),
+							Port:     443,
+							Protocol: gatewayv1.HTTPSProtocolType,
+						},
+						{
+							Name:     "internal",
+							Hostname: hostnamePtr("*.internal.selection.local"),
+							Port:     443,
+							Protocol: gatewayv1.HTTPSProtocolType,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, gw)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, gw) }()
+
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-select-listener",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "app.internal.selection.local",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: HTTPRoute targets the more specific listener
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-select-listener"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, routeKey, route)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(route.Spec.ParentRefs).To(HaveLen(1))
+			Expect(string(route.Spec.ParentRefs[0].Name)).To(Equal("selection-gateway"))
+			Expect(string(*route.Spec.ParentRefs[0].SectionName)).To(Equal("internal"))
+		})
+
+		It("should fall back to the default gateway when no listener matches", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service whose hostname matches no listener
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-select-fallback",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "fallback.unmatched.local",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: HTTPRoute uses the controller defaults
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-select-fallback"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, routeKey, route)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(string(route.Spec.ParentRefs[0].Name)).To(Equal("test-gateway"))
+			Expect(string(*route.Spec.ParentRefs[0].SectionName)).To(Equal("https"))
+		})
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewayIndexKey indexes exposed Services by the Gateways they may attach to
+const gatewayIndexKey = "httproute.controller/gateway"
+
+// anyGateway is the index value for Services choosing a Gateway in a namespace automatically
+const anyGateway = "*"
+
+// indexGatewayTarget returns namespace/name from the gateway annotations of an
+// exposed Service that targets a Gateway explicitly, or namespace/* when it
+// selects a listener automatically. Values only depend on the annotations, an
+// empty namespace or name stands for the configured default, which
+// requestsForGateway resolves, so the index stays valid when the
+// ControllerConfig changes.
+func (r *ServiceReconciler) indexGatewayTarget(obj client.Object) []string {
+	svc, ok := obj.(*corev1.Service)
+	if !ok || !r.isExposed(svc) {
+		return nil
+	}
+	namespace := r.annotation(svc, AnnotationGatewayNamespace)
+	if r.annotation(svc, AnnotationGateway) != "" || r.annotation(svc, AnnotationSectionName) != "" {
+		return []string{gatewayIndexValue(namespace, r.annotation(svc, AnnotationGateway))}
+	}
+	return []string{gatewayIndexValue(namespace, anyGateway)}
+}
+
+func gatewayIndexValue(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// gatewayIndexValues returns the index values of the Services that may attach
+// to the Gateway namespace/name under the current configuration.
+func (r *ServiceReconciler) gatewayIndexValues(namespace, name string) []string {
+	cfg := r.config()
+	values := []string{gatewayIndexValue(namespace, name), gatewayIndexValue(namespace, anyGateway)}
+	if namespace == cfg.DefaultGatewayNamespace {
+		values = append(values, gatewayIndexValue("", name))
+	}
+	if name == cfg.DefaultGateway {
+		values = append(values, gatewayIndexValue(namespace, ""))
+		if namespace == cfg.DefaultGatewayNamespace {
+			values = append(values, gatewayIndexValue("", ""))
+		}
+	}
+	if slices.Contains(r.candidateGatewayNamespaces(""), namespace) {
+		values = append(values, gatewayIndexValue("", anyGateway))
+	}
+	return values
+}
+
+// requestsForGateway maps a Gateway to the Services that target it explicitly,
+// directly or through Namespace defaults, ExposureRules and ExposureClasses,
+// or may select one of its listeners.
+func (r *ServiceReconciler) requestsForGateway(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := append(r.requestsForNamespaceDefaults(ctx, obj), r.requestsForRuleGateway(ctx, obj)...)
+	requests = append(requests, r.requestsForClassGateway(ctx, obj)...)
+	for _, value := range r.gatewayIndexValues(obj.GetNamespace(), obj.GetName()) {
+		services := &corev1.ServiceList{}
+		if err := r.List(ctx, services, client.MatchingFields{gatewayIndexKey: value}); err != nil {
+			return nil
+		}
+		for i := range services.Items {
+			if !r.matchesClass(&services.Items[i]) {
+				continue
+			}
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: services.Items[i].Name, Namespace: services.Items[i].Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// checkGatewayTarget records whether the target Gateway and listener exist.
+// The route is created either way, so it attaches as soon as they appear.
+func (r *ServiceReconciler) checkGatewayTarget(ctx context.Context, svc *corev1.Service, target gatewayTarget) error {
+	gw := &gatewayv1.Gateway{}
+	err := r.Get(ctx, types.NamespacedName{Name: target.Name, Namespace: target.Namespace}, gw)
+	if errors.IsNotFound(err) {
+		return r.setCondition(ctx, svc, ConditionGatewayResolved, metav1.ConditionFalse, ReasonGatewayNotFound,
+			fmt.Sprintf("Gateway %s/%s not found", target.Namespace, target.Name))
+	}
+	if err != nil {
+		return err
+	}
+	for _, listener := range gw.Spec.Listeners {
+		if string(listener.Name) == target.SectionName {
+			return r.setCondition(ctx, svc, ConditionGatewayResolved, metav1.ConditionTrue, ReasonResolved,
+				fmt.Sprintf("Gateway %s/%s has listener %s", target.Namespace, target.Name, target.SectionName))
+		}
+	}
+	return r.setCondition(ctx, svc, ConditionGatewayResolved, metav1.ConditionFalse, ReasonListenerNotFound,
+		fmt.Sprintf("Gateway %s/%s has no listener %s", target.Namespace, target.Name, target.SectionName))
+}
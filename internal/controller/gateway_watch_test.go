@@ -0,0 +1,125 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Gateway watch", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When the configuration changes after Services were indexed", func() {
+		It("should resolve the defaults when a Gateway changes", func() {
+			base := Config{DefaultGateway: "gw", DefaultGatewayNamespace: "gateways"}
+			store := NewConfigStore(base)
+			r := &ServiceReconciler{Config: base, Store: store}
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name:        "web",
+				Namespace:   "default",
+				Annotations: map[string]string{"httproute.controller/expose": "true"},
+			}}
+			Expect(r.indexGatewayTarget(svc)).To(ConsistOf("/*"))
+			Expect(r.gatewayIndexValues("shared", "public")).NotTo(ContainElement("/*"))
+
+			// ACT: Add a gateway namespace
+			cfg := base
+			cfg.GatewayNamespaces = []string{"shared"}
+			store.set(cfg)
+
+			// ASSERT: The indexed Service is found for Gateways in the new namespace
+			Expect(r.indexGatewayTarget(svc)).To(ConsistOf("/*"))
+			Expect(r.gatewayIndexValues("shared", "public")).To(ContainElement("/*"))
+			Expect(r.gatewayIndexValues("gateways", "gw")).To(ContainElements("/", "gateways/", "/gw"))
+		})
+	})
+
+	Context("When a Service is exposed before its Gateway exists", func() {
+		It("should resolve the Gateway once it is created", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service targeting a missing Gateway
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-late-gateway",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":            "true",
+						"httproute.controller/hostname":          "late.example.com",
+						"httproute.controller/gateway":           "late-gateway",
+						"httproute.controller/gateway-namespace": "custom-ns",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			gatewayReason := func() string {
+				current := &corev1.Service{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, current); err != nil {
+					return ""
+				}
+				cond := meta.FindStatusCondition(current.Status.Conditions, ConditionGatewayResolved)
+				if cond == nil {
+					return ""
+				}
+				return cond.Reason
+			}
+
+			// ASSERT: Route is created but the Gateway is reported missing
+			Eventually(gatewayReason, timeout, interval).Should(Equal(ReasonGatewayNotFound))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      routeName("default", "test-svc-late-gateway"),
+				Namespace: "custom-ns",
+			}, &gatewayv1.HTTPRoute{})).Should(Succeed())
+
+			// ACT: Create the Gateway
+			gw := &gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "late-gateway",
+					Namespace: "custom-ns",
+				},
+				Spec: gatewayv1.GatewaySpec{
+					GatewayClassName: "test-class",
+					Listeners: []gatewayv1.Listener{
+						{
+							Name:     "https",
+							Port:     443,
+							Protocol: gatewayv1.HTTPSProtocolType,
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, gw)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, gw) }()
+
+			// ASSERT: Service is requeued and the Gateway resolved
+			Eventually(gatewayReason, timeout, interval).Should(Equal(ReasonResolved))
+		})
+	})
+})
@@ -0,0 +1,313 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+// hostnameIndexKey indexes exposed Services and ExposedServices by the hostnames they claim
+const hostnameIndexKey = "httproute.controller/hostname-claim"
+
+// hostnameClaimKey normalizes a hostname into the key used for conflict detection.
+func hostnameClaimKey(hostname string) string {
+	return strings.ToLower(hostname)
+}
+
+// indexHostnameClaim returns the hostname claimed by an exposed Service,
+// either set explicitly or generated from a hostname template. The controller
+// class is checked by hostnameClaimants, so the index does not depend on the
+// configuration.
+func (r *ServiceReconciler) indexHostnameClaim(obj client.Object) []string {
+	svc, ok := obj.(*corev1.Service)
+	if !ok || !r.isExposed(svc) {
+		return nil
+	}
+	hostname := r.annotation(svc, AnnotationHostname)
+	if hostname == "" {
+		hostname = r.annotation(svc, AnnotationGeneratedHostname)
+	}
+	if hostname == "" {
+		return nil
+	}
+	return []string{hostnameClaimKey(hostname)}
+}
+
+// indexExposedHostnameClaims returns the hostnames claimed by an ExposedService.
+func indexExposedHostnameClaims(obj client.Object) []string {
+	es, ok := obj.(*httproutev1alpha1.ExposedService)
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(es.Spec.Hostnames))
+	for _, hostname := range es.Spec.Hostnames {
+		keys = append(keys, hostnameClaimKey(string(hostname)))
+	}
+	return keys
+}
+
+// hostnameClaims returns the claim keys of a Service or ExposedService.
+func (r *ServiceReconciler) hostnameClaims(obj client.Object) []string {
+	if _, ok := obj.(*httproutev1alpha1.ExposedService); ok {
+		return indexExposedHostnameClaims(obj)
+	}
+	return r.indexHostnameClaim(obj)
+}
+
+// hostnameClaimants lists the Services of the controller class, or the
+// ExposedServices when exposed is set, claiming the hostname key.
+// ExposedServices only claim hostnames when this instance reconciles them.
+func (r *ServiceReconciler) hostnameClaimants(ctx context.Context, key string, exposed bool) ([]client.Object, error) {
+	var claimants []client.Object
+	if exposed {
+		if !r.handlesResources() {
+			return nil, nil
+		}
+		list := &httproutev1alpha1.ExposedServiceList{}
+		if err := r.List(ctx, list, client.MatchingFields{hostnameIndexKey: key}); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			claimants = append(claimants, &list.Items[i])
+		}
+		return claimants, nil
+	}
+	list := &corev1.ServiceList{}
+	if err := r.List(ctx, list, client.MatchingFields{hostnameIndexKey: key}); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if r.matchesClass(&list.Items[i]) {
+			claimants = append(claimants, &list.Items[i])
+		}
+	}
+	return claimants, nil
+}
+
+// hostnameOwner returns the Service or ExposedService that owns the hostname:
+// the claimant with the oldest creation timestamp, with namespace/name as tie
+// breaker. Claimants being deleted or denied by hostname policy hold no claim.
+func (r *ServiceReconciler) hostnameOwner(
+	ctx context.Context, claimant client.Object, hostname string,
+) (client.Object, error) {
+	owner := claimant
+	for _, exposed := range []bool{false, true} {
+		candidates, err := r.hostnameClaimants(ctx, hostnameClaimKey(hostname), exposed)
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range candidates {
+			if !holdsHostnameClaim(candidate) || !pathClaimsOverlap(pathClaims(claimant), pathClaims(candidate)) {
+				continue
+			}
+			if claimedEarlier(candidate, owner) {
+				owner = candidate
+			}
+		}
+	}
+	return owner, nil
+}
+
+// pathClaim is a path an ExposedService routes on its hostnames, either one
+// exact path or every path under a prefix.
+type pathClaim struct {
+	exact bool
+	path  string
+}
+
+// pathClaims returns the normalized paths obj routes, or nil when it routes
+// every path of its hostnames, like a Service does. Matches without a path,
+// on the root prefix or on a regular expression count as every path.
+func pathClaims(obj client.Object) []pathClaim {
+	es, ok := obj.(*httproutev1alpha1.ExposedService)
+	if !ok || len(es.Spec.Rules) == 0 {
+		return nil
+	}
+	var claims []pathClaim
+	for _, rule := range es.Spec.Rules {
+		if len(rule.Matches) == 0 {
+			return nil
+		}
+		for _, match := range rule.Matches {
+			claim, ok := normalizePathMatch(match.Path)
+			if !ok {
+				return nil
+			}
+			claims = append(claims, claim)
+		}
+	}
+	return claims
+}
+
+// normalizePathMatch returns the path claimed by match, or false when it may
+// match every path.
+func normalizePathMatch(match *gatewayv1.HTTPPathMatch) (pathClaim, bool) {
+	if match == nil || match.Value == nil {
+		return pathClaim{}, false
+	}
+	matchType := gatewayv1.PathMatchPathPrefix
+	if match.Type != nil {
+		matchType = *match.Type
+	}
+	switch matchType {
+	case gatewayv1.PathMatchExact:
+		return pathClaim{exact: true, path: *match.Value}, true
+	case gatewayv1.PathMatchPathPrefix:
+		if path := strings.TrimSuffix(*match.Value, "/"); path != "" {
+			return pathClaim{path: path}, true
+		}
+	}
+	return pathClaim{}, false
+}
+
+// covers reports whether a request for path matches the claim.
+func (c pathClaim) covers(path string) bool {
+	if c.exact {
+		return path == c.path
+	}
+	return path == c.path || strings.HasPrefix(path, c.path+"/")
+}
+
+// overlaps reports whether some request path matches both claims.
+func (c pathClaim) overlaps(other pathClaim) bool {
+	switch {
+	case c.exact:
+		return other.covers(c.path)
+	case other.exact:
+		return c.covers(other.path)
+	}
+	return c.covers(other.path) || other.covers(c.path)
+}
+
+// pathClaimsOverlap reports whether two claimants of a hostname route a common
+// path. nil claims every path.
+func pathClaimsOverlap(a, b []pathClaim) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x.overlaps(y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hostnameConflict returns a message when an earlier claimant owns hostname.
+func (r *ServiceReconciler) hostnameConflict(
+	ctx context.Context, claimant client.Object, hostname string,
+) (string, error) {
+	owner, err := r.hostnameOwner(ctx, claimant, hostname)
+	if err != nil {
+		return "", err
+	}
+	if owner.GetUID() == claimant.GetUID() {
+		return "", nil
+	}
+	return fmt.Sprintf("hostname %s is already claimed by %s %s/%s",
+		hostname, claimantKind(owner), owner.GetNamespace(), owner.GetName()), nil
+}
+
+// releasedClaimReasons are the Exposed reasons of claimants that gave up
+// their hostname: refused it by policy, or not routed by their schedule.
+var releasedClaimReasons = []string{ReasonHostnameDenied, ReasonExpired, ReasonOutsideWindow, ReasonInvalidSchedule}
+
+// holdsHostnameClaim reports whether a claimant still holds its claim.
+func holdsHostnameClaim(obj client.Object) bool {
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return false
+	}
+	var conditions []metav1.Condition
+	switch claimant := obj.(type) {
+	case *corev1.Service:
+		conditions = claimant.Status.Conditions
+	case *httproutev1alpha1.ExposedService:
+		conditions = claimant.Status.Conditions
+	}
+	cond := meta.FindStatusCondition(conditions, ConditionExposed)
+	return cond == nil || cond.Status != metav1.ConditionFalse || !slices.Contains(releasedClaimReasons, cond.Reason)
+}
+
+// claimantKind returns the kind of a claimant for messages.
+func claimantKind(obj client.Object) string {
+	if _, ok := obj.(*httproutev1alpha1.ExposedService); ok {
+		return "ExposedService"
+	}
+	return "Service"
+}
+
+// claimedEarlier reports whether a was created before b. Services win ties
+// against ExposedServices of the same name.
+func claimedEarlier(a, b client.Object) bool {
+	createdA, createdB := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !createdA.Equal(&createdB) {
+		return createdA.Before(&createdB)
+	}
+	if a.GetNamespace() != b.GetNamespace() {
+		return a.GetNamespace() < b.GetNamespace()
+	}
+	if a.GetName() != b.GetName() {
+		return a.GetName() < b.GetName()
+	}
+	return claimantKind(a) > claimantKind(b)
+}
+
+// hostnameClaimHandler requeues every Service, or every ExposedService when
+// exposed is set, sharing a hostname with the changed object, both before and
+// after the change, so a losing claimant is reconsidered as soon as the winner
+// releases the hostname.
+func (r *ServiceReconciler) hostnameClaimHandler(exposed bool) handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.enqueueHostnameClaimants(ctx, q, e.Object, exposed)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.enqueueHostnameClaimants(ctx, q, e.ObjectOld, exposed)
+			r.enqueueHostnameClaimants(ctx, q, e.ObjectNew, exposed)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			r.enqueueHostnameClaimants(ctx, q, e.Object, exposed)
+		},
+	}
+}
+
+func (r *ServiceReconciler) enqueueHostnameClaimants(
+	ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object, exposed bool,
+) {
+	for _, key := range r.hostnameClaims(obj) {
+		claimants, err := r.hostnameClaimants(ctx, key, exposed)
+		if err != nil {
+			continue
+		}
+		for _, claimant := range claimants {
+			if claimant.GetUID() == obj.GetUID() {
+				continue
+			}
+			q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(claimant)})
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Hostname conflict detection", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	newService := func(name, hostname string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Annotations: map[string]string{
+					"httproute.controller/expose":   "true",
+					"httproute.controller/hostname": hostname,
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					},
+				},
+			},
+		}
+	}
+
+	Context("When two Services claim the same hostname", func() {
+		It("should only route the first claimant and report a conflict on the second", func() {
+			ctx := context.Background()
+
+			// ARRANGE: First claimant gets its route
+			first := newService("test-svc-conflict-first", "conflict.homelab.local")
+			Expect(k8sClient.Create(ctx, first)).Should(Succeed())
+
+			firstRouteKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-conflict-first"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, firstRouteKey, &gatewayv1.HTTPRoute{})
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			// ACT: Second claimant with the same hostname (different case)
+			second := newService("test-svc-conflict-second", "Conflict.homelab.local")
+			Expect(k8sClient.Create(ctx, second)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, second) }()
+
+			// ASSERT: Second Service reports the conflict
+			secondKey := types.NamespacedName{Name: second.Name, Namespace: second.Namespace}
+			Eventually(func() string {
+				svc := &corev1.Service{}
+				if err := k8sClient.Get(ctx, secondKey, svc); err != nil {
+					return ""
+				}
+				cond := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed)
+				if cond == nil || cond.Status != metav1.ConditionFalse {
+					return ""
+				}
+				return cond.Reason
+			}, timeout, interval).Should(Equal(ReasonHostnameConflict))
+
+			// ASSERT: No route for the second claimant
+			secondRouteKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-conflict-second"),
+				Namespace: "envoy-gateway-system",
+			}
+			err := k8sClient.Get(ctx, secondRouteKey, &gatewayv1.HTTPRoute{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "HTTPRoute should not be created for a losing claimant")
+
+			// ACT: First claimant releases the hostname
+			Expect(k8sClient.Delete(ctx, first)).Should(Succeed())
+
+			// ASSERT: Second claimant now gets its route
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, secondRouteKey, &gatewayv1.HTTPRoute{})
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,206 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+// Namespace annotations restricting the hostnames Services in the namespace may claim
+const (
+	AnnotationAllowedHostnames = AnnotationPrefix + "/allowed-hostnames"
+	AnnotationDeniedHostnames  = AnnotationPrefix + "/denied-hostnames"
+)
+
+// hostnameRestriction is one source of allowed and denied hostname patterns
+type hostnameRestriction struct {
+	source  string
+	allowed []string
+	denied  []string
+}
+
+// checkHostnamePolicy returns a message describing why the Service may not
+// claim hostname, or an empty string when the hostname is permitted. Denials
+// win; every source with an allow list must allow the hostname. A wildcard
+// hostname is denied when it overlaps a denied pattern and only allowed when
+// an allowed pattern contains all of it.
+func (r *ServiceReconciler) checkHostnamePolicy(
+	ctx context.Context, svc *corev1.Service, hostname string,
+) (string, error) {
+	restrictions, err := r.hostnameRestrictions(ctx, svc.Namespace)
+	if err != nil {
+		return "", err
+	}
+
+	for _, restriction := range restrictions {
+		for _, pattern := range restriction.denied {
+			if hostnamePatternsOverlap(pattern, hostname) {
+				return fmt.Sprintf("hostname %s is denied by %s", hostname, restriction.source), nil
+			}
+		}
+	}
+	for _, restriction := range restrictions {
+		if len(restriction.allowed) == 0 {
+			continue
+		}
+		allowed := false
+		for _, pattern := range restriction.allowed {
+			if hostnamePatternContains(pattern, hostname) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("hostname %s is not allowed by %s", hostname, restriction.source), nil
+		}
+	}
+	return "", nil
+}
+
+// hostnameRestrictions collects the Namespace annotations and every
+// HostnamePolicy selecting the namespace.
+func (r *ServiceReconciler) hostnameRestrictions(ctx context.Context, namespace string) ([]hostnameRestriction, error) {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return nil, err
+	}
+
+	var restrictions []hostnameRestriction
+	if r.annotation(ns, AnnotationAllowedHostnames) != "" || r.annotation(ns, AnnotationDeniedHostnames) != "" {
+		restrictions = append(restrictions, hostnameRestriction{
+			source:  fmt.Sprintf("Namespace %s", ns.Name),
+			allowed: splitList(r.annotation(ns, AnnotationAllowedHostnames)),
+			denied:  splitList(r.annotation(ns, AnnotationDeniedHostnames)),
+		})
+	}
+
+	policies := &httproutev1alpha1.HostnamePolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		return nil, err
+	}
+	for _, policy := range policies.Items {
+		selector := labels.Everything()
+		if policy.Spec.NamespaceSelector != nil {
+			var err error
+			selector, err = metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("HostnamePolicy %s has an invalid namespaceSelector: %w", policy.Name, err)
+			}
+		}
+		if !selector.Matches(labels.Set(ns.Labels)) {
+			continue
+		}
+		restrictions = append(restrictions, hostnameRestriction{
+			source:  fmt.Sprintf("HostnamePolicy %s", policy.Name),
+			allowed: policy.Spec.AllowedHostnames,
+			denied:  policy.Spec.DeniedHostnames,
+		})
+	}
+
+	cfg := r.config()
+	if len(cfg.AllowedHostnames) > 0 || len(cfg.DeniedHostnames) > 0 {
+		restrictions = append(restrictions, hostnameRestriction{
+			source:  "ControllerConfig",
+			allowed: cfg.AllowedHostnames,
+			denied:  cfg.DeniedHostnames,
+		})
+	}
+	return restrictions, nil
+}
+
+// matchesHostnamePattern reports whether hostname equals pattern or falls
+// under a wildcard pattern such as *.team-a.example.com.
+func matchesHostnamePattern(pattern, hostname string) bool {
+	if pattern == "" {
+		return false
+	}
+	hostnamePattern := gatewayv1.Hostname(pattern)
+	return hostnameMatchScore(&hostnamePattern, hostname) >= 0
+}
+
+// hostnamePatternsOverlap reports whether some hostname matches both pattern
+// and the claimed hostname, which may itself be a wildcard.
+func hostnamePatternsOverlap(pattern, hostname string) bool {
+	suffix, wildcard := strings.CutPrefix(strings.ToLower(hostname), "*")
+	if !wildcard {
+		return matchesHostnamePattern(pattern, hostname)
+	}
+	if patternSuffix, ok := strings.CutPrefix(strings.ToLower(pattern), "*"); ok {
+		return strings.HasSuffix(suffix, patternSuffix) || strings.HasSuffix(patternSuffix, suffix)
+	}
+	return matchesHostnamePattern(hostname, pattern)
+}
+
+// hostnamePatternContains reports whether every hostname matching the claimed
+// hostname, which may be a wildcard, also matches pattern.
+func hostnamePatternContains(pattern, hostname string) bool {
+	suffix, wildcard := strings.CutPrefix(strings.ToLower(hostname), "*")
+	if !wildcard {
+		return matchesHostnamePattern(pattern, hostname)
+	}
+	patternSuffix, ok := strings.CutPrefix(strings.ToLower(pattern), "*")
+	return ok && strings.HasSuffix(suffix, patternSuffix)
+}
+
+// splitList splits a comma-separated annotation value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// requestsForExposedServices requeues every exposed Service after a policy change.
+func (r *ServiceReconciler) requestsForExposedServices(ctx context.Context, _ client.Object) []reconcile.Request {
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services); err != nil {
+		return nil
+	}
+	return r.exposedServiceRequests(services.Items)
+}
+
+// requestsForNamespaceServices requeues the exposed Services in a Namespace
+// after its annotations change, or all of them when it auto-exposes Services.
+func (r *ServiceReconciler) requestsForNamespaceServices(ctx context.Context, obj client.Object) []reconcile.Request {
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, client.InNamespace(obj.GetName())); err != nil {
+		return nil
+	}
+	if r.annotation(obj, AnnotationAutoExpose) != "true" {
+		return r.exposedServiceRequests(services.Items)
+	}
+	requests := make([]reconcile.Request, 0, len(services.Items))
+	for i := range services.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&services.Items[i])})
+	}
+	return requests
+}
+
+func (r *ServiceReconciler) exposedServiceRequests(services []corev1.Service) []reconcile.Request {
+	var requests []reconcile.Request
+	for i := range services {
+		if r.isExposed(&services[i]) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&services[i])})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("Hostname policy", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	newService := func(name, namespace, hostname string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					"httproute.controller/expose":   "true",
+					"httproute.controller/hostname": hostname,
+				},
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Port:       80,
+						TargetPort: intstr.FromInt(8080),
+					},
+				},
+			},
+		}
+	}
+
+	exposedReason := func(ctx context.Context, key types.NamespacedName) func() string {
+		return func() string {
+			svc := &corev1.Service{}
+			if err := k8sClient.Get(ctx, key, svc); err != nil {
+				return ""
+			}
+			cond := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed)
+			if cond == nil {
+				return ""
+			}
+			return cond.Reason
+		}
+	}
+
+	Context("When matching hostname patterns", func() {
+		It("should match exact hostnames and subdomains of wildcards", func() {
+			Expect(matchesHostnamePattern("login.example.com", "login.example.com")).To(BeTrue())
+			Expect(matchesHostnamePattern("*.team-a.example.com", "app.team-a.example.com")).To(BeTrue())
+			Expect(matchesHostnamePattern("*.team-a.example.com", "team-a.example.com")).To(BeFalse())
+			Expect(matchesHostnamePattern("*.team-a.example.com", "app.team-b.example.com")).To(BeFalse())
+			Expect(matchesHostnamePattern("", "app.example.com")).To(BeFalse())
+		})
+	})
+
+	Context("When a claimed hostname is a wildcard", func() {
+		It("should deny overlaps with denied patterns and allow only contained wildcards", func() {
+			Expect(hostnamePatternsOverlap("login.example.com", "*.example.com")).To(BeTrue())
+			Expect(hostnamePatternsOverlap("*.internal.example.com", "*.example.com")).To(BeTrue())
+			Expect(hostnamePatternsOverlap("*.example.com", "*.team-a.example.com")).To(BeTrue())
+			Expect(hostnamePatternsOverlap("login.example.com", "*.team-a.example.com")).To(BeFalse())
+			Expect(hostnamePatternsOverlap("example.com", "*.example.com")).To(BeFalse())
+
+			Expect(hostnamePatternContains("*.example.com", "*.team-a.example.com")).To(BeTrue())
+			Expect(hostnamePatternContains("*.team-a.example.com", "*.team-a.example.com")).To(BeTrue())
+			Expect(hostnamePatternContains("*.team-a.example.com", "*.example.com")).To(BeFalse())
+			Expect(hostnamePatternContains("app.example.com", "*.example.com")).To(BeFalse())
+		})
+
+		It("should refuse a wildcard covering a denied hostname", func() {
+			ctx := context.Background()
+			r := &ServiceReconciler{Client: k8sClient, Scheme: k8sClient.Scheme()}
+
+			// ARRANGE: Namespace denying login.example.com
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-hostname-wildcard-ns",
+				Annotations: map[string]string{"httproute.controller/denied-hostnames": "login.example.com"},
+			}}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: ns.Name}}
+
+			// ACT: Claim a wildcard covering it
+			violation, err := r.checkHostnamePolicy(ctx, svc, "*.example.com")
+
+			// ASSERT: The wildcard is denied
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violation).To(ContainSubstring("is denied by Namespace test-hostname-wildcard-ns"))
+		})
+	})
+
+	Context("When a HostnamePolicy denies a hostname cluster-wide", func() {
+		It("should refuse to create the HTTPRoute", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Cluster-wide denial
+			policy := &httproutev1alpha1.HostnamePolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "deny-login"},
+				Spec: httproutev1alpha1.HostnamePolicySpec{
+					DeniedHostnames: []string{"login.policy.local"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, policy) }()
+
+			svc := newService("test-svc-policy-denied", "default", "login.policy.local")
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: Service reports the denial
+			key := types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}
+			Eventually(exposedReason(ctx, key), timeout, interval).Should(Equal(ReasonHostnameDenied))
+
+			// ASSERT: No HTTPRoute
+			routeKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-policy-denied"),
+				Namespace: "envoy-gateway-system",
+			}
+			err := k8sClient.Get(ctx, routeKey, &gatewayv1.HTTPRoute{})
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "HTTPRoute should not be created for a denied hostname")
+		})
+	})
+
+	Context("When a Namespace restricts allowed hostnames", func() {
+		It("should only route hostnames under the allowed domain", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Namespace limited to its own subdomain
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "team-a",
+					Annotations: map[string]string{
+						"httproute.controller/allowed-hostnames": "*.team-a.policy.local",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+			allowed := newService("test-svc-policy-allowed", "team-a", "app.team-a.policy.local")
+			Expect(k8sClient.Create(ctx, allowed)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, allowed) }()
+
+			foreign := newService("test-svc-policy-foreign", "team-a", "app.team-b.policy.local")
+			Expect(k8sClient.Create(ctx, foreign)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, foreign) }()
+
+			// ASSERT: Allowed hostname is routed
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      routeName("team-a", "test-svc-policy-allowed"),
+					Namespace: "envoy-gateway-system",
+				}, &gatewayv1.HTTPRoute{})
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			// ASSERT: Foreign hostname is denied
+			foreignKey := types.NamespacedName{Name: foreign.Name, Namespace: foreign.Namespace}
+			Eventually(exposedReason(ctx, foreignKey), timeout, interval).Should(Equal(ReasonHostnameDenied))
+		})
+	})
+})
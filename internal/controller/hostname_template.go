@@ -0,0 +1,151 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// AnnotationHostnameTemplate overrides the controller hostname template for a Namespace
+	AnnotationHostnameTemplate = AnnotationPrefix + "/hostname-template"
+	// AnnotationGeneratedHostname records the hostname derived from a template
+	// or ExposureRule, so conflict detection can index it
+	AnnotationGeneratedHostname = AnnotationPrefix + "/generated-hostname"
+)
+
+// hostnameTemplateData is the data available to hostname templates
+type hostnameTemplateData struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	ClusterName string
+	// Domain is the wildcard domain of the target listener, e.g. apps.example.com for *.apps.example.com
+	Domain string
+}
+
+// ParseHostnameTemplate parses a hostname template such as
+// {{.Name}}.{{.Namespace}}.apps.example.com.
+func ParseHostnameTemplate(text string) (*template.Template, error) {
+	return template.New("hostname").Option("missingkey=error").Parse(text)
+}
+
+// resolveHostname returns the Service hostname: the hostname annotation when
+// set, otherwise the ExposureRule hostname or the rendered Namespace hostname
+// template, Namespace hostname suffix or controller hostname template. The
+// boolean reports whether the hostname was generated, i.e. not annotated.
+func (r *ServiceReconciler) resolveHostname(ctx context.Context, svc *corev1.Service) (string, bool, error) {
+	if hostname := r.annotation(svc, AnnotationHostname); hostname != "" {
+		return hostname, false, nil
+	}
+
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return "", false, err
+	}
+	if hostname := r.setting(s, AnnotationHostname); hostname != "" {
+		return hostname, true, nil
+	}
+	ns := s.ns
+	text := r.annotation(ns, AnnotationHostnameTemplate)
+	if s.class != nil && s.class.Spec.HostnameTemplate != "" {
+		text = s.class.Spec.HostnameTemplate
+	}
+	if suffix := r.annotation(ns, AnnotationHostnameSuffix); text == "" && suffix != "" {
+		text = "{{.Name}}." + suffix
+	}
+	if text == "" {
+		text = r.config().HostnameTemplate
+	}
+	if text == "" {
+		return "", false, nil
+	}
+
+	domain, err := r.listenerDomain(ctx, svc)
+	if err != nil {
+		return "", false, err
+	}
+	hostname, err := renderHostname(text, hostnameTemplateData{
+		Name:        svc.Name,
+		Namespace:   svc.Namespace,
+		Labels:      svc.Labels,
+		ClusterName: r.config().ClusterName,
+		Domain:      domain,
+	})
+	if err != nil {
+		return "", true, err
+	}
+	return hostname, true, nil
+}
+
+// renderHostname renders a hostname template and validates the result.
+func renderHostname(text string, data hostnameTemplateData) (string, error) {
+	tmpl, err := ParseHostnameTemplate(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid hostname template: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("rendering hostname template: %w", err)
+	}
+	hostname := strings.ToLower(strings.TrimSpace(sb.String()))
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return "", fmt.Errorf("hostname template rendered invalid hostname %q: %s", hostname, strings.Join(errs, ", "))
+	}
+	return hostname, nil
+}
+
+// listenerDomain returns the wildcard domain of the listener the Service
+// targets explicitly or by default, or an empty string when the listener has
+// no wildcard hostname or does not exist.
+func (r *ServiceReconciler) listenerDomain(ctx context.Context, svc *corev1.Service) (string, error) {
+	target, err := r.explicitTarget(ctx, svc)
+	if err != nil {
+		return "", err
+	}
+	key := types.NamespacedName{
+		Name:      valueOrDefault(target.Name, r.config().DefaultGateway),
+		Namespace: valueOrDefault(target.Namespace, r.config().DefaultGatewayNamespace),
+	}
+	sectionName := valueOrDefault(target.SectionName, r.config().DefaultSectionName)
+
+	gw := &gatewayv1.Gateway{}
+	if err := r.Get(ctx, key, gw); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	for _, listener := range gw.Spec.Listeners {
+		if string(listener.Name) != sectionName || listener.Hostname == nil {
+			continue
+		}
+		if domain, ok := strings.CutPrefix(string(*listener.Hostname), "*."); ok {
+			return domain, nil
+		}
+	}
+	return "", nil
+}
+
+// recordGeneratedHostname keeps AnnotationGeneratedHostname in sync with the
+// hostname derived from a template, removing it when none was generated.
+func (r *ServiceReconciler) recordGeneratedHostname(ctx context.Context, svc *corev1.Service, hostname string) error {
+	return r.patchAnnotations(ctx, svc, map[string]string{AnnotationGeneratedHostname: hostname})
+}
+
+func valueOrDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Hostname templates", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When rendering a hostname template", func() {
+		It("should substitute Service fields, labels, cluster name and domain", func() {
+			hostname, err := renderHostname(
+				"{{.Name}}.{{.Namespace}}.{{.Labels.team}}.{{.ClusterName}}.{{.Domain}}",
+				hostnameTemplateData{
+					Name:        "api",
+					Namespace:   "dev",
+					Labels:      map[string]string{"team": "payments"},
+					ClusterName: "eu1",
+					Domain:      "apps.example.com",
+				})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hostname).To(Equal("api.dev.payments.eu1.apps.example.com"))
+		})
+
+		It("should reject templates referencing missing labels or rendering invalid hostnames", func() {
+			_, err := renderHostname("{{.Name}}.{{.Labels.missing}}.example.com",
+				hostnameTemplateData{Name: "api", Labels: map[string]string{}})
+			Expect(err).To(HaveOccurred())
+
+			_, err = renderHostname("{{.Name}}_{{.Namespace}}.example.com",
+				hostnameTemplateData{Name: "api", Namespace: "dev"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When a Namespace overrides the hostname template", func() {
+		It("should derive the hostname for Services without hostname annotation", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Namespace with a hostname template
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "templated",
+					Annotations: map[string]string{
+						"httproute.controller/hostname-template": "{{.Name}}.{{.Namespace}}.apps.local",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-templated",
+					Namespace: "templated",
+					Annotations: map[string]string{
+						"httproute.controller/expose": "true",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: HTTPRoute uses the generated hostname
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("templated", "test-svc-templated"),
+				Namespace: "envoy-gateway-system",
+			}
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, routeKey, route)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("test-svc-templated.templated.apps.local")))
+
+			// ASSERT: Generated hostname is recorded for conflict detection
+			Eventually(func() string {
+				current := &corev1.Service{}
+				if err := k8sClient.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, current); err != nil {
+					return ""
+				}
+				return current.Annotations[AnnotationGeneratedHostname]
+			}, timeout, interval).Should(Equal("test-svc-templated.templated.apps.local"))
+		})
+	})
+})
@@ -0,0 +1,141 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+// AnnotationHostnameSuffix on a Namespace derives the hostname <service>.<suffix>
+// for Services without a hostname annotation. The gateway, gateway-namespace,
+// section-name, skip-reference-grant, exposure-class, expose-ttl and
+// expose-window annotations also default the Service annotations of the same
+// name when set on a Namespace.
+const AnnotationHostnameSuffix = AnnotationPrefix + "/hostname-suffix"
+
+// namespaceDefaultKeys are the Service annotations a Namespace can default
+var namespaceDefaultKeys = []string{
+	AnnotationGateway, AnnotationGatewayNamespace, AnnotationSectionName, AnnotationSkipReferenceGrant,
+	AnnotationExposureClass, AnnotationExposeTTL, AnnotationExposeWindow,
+}
+
+// serviceSettings holds the sources the settings of a Service resolve from
+type serviceSettings struct {
+	svc  *corev1.Service
+	rule *httproutev1alpha1.ExposureRule
+	ns   *corev1.Namespace
+	// className is the selected ExposureClass; class is nil when it does not exist
+	className string
+	class     *httproutev1alpha1.ExposureClass
+}
+
+// settings collects the ExposureRule, Namespace and ExposureClass of svc.
+func (r *ServiceReconciler) settings(ctx context.Context, svc *corev1.Service) (serviceSettings, error) {
+	ns, err := r.serviceNamespace(ctx, svc)
+	if err != nil {
+		return serviceSettings{}, err
+	}
+	rule, err := r.exposureRuleFor(ctx, svc)
+	if err != nil {
+		return serviceSettings{}, err
+	}
+	s := serviceSettings{svc: svc, rule: rule, ns: ns}
+	if s.className = r.setting(s, AnnotationExposureClass); s.className != "" {
+		if s.class, err = r.exposureClass(ctx, s.className); err != nil {
+			return serviceSettings{}, err
+		}
+	}
+	return s, nil
+}
+
+// serviceNamespace returns the Namespace of svc, or an empty one when it is gone.
+func (r *ServiceReconciler) serviceNamespace(ctx context.Context, svc *corev1.Service) (*corev1.Namespace, error) {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: svc.Namespace}, ns); err != nil {
+		if errors.IsNotFound(err) {
+			return &corev1.Namespace{}, nil
+		}
+		return nil, err
+	}
+	return ns, nil
+}
+
+// setting returns the Service annotation key, falling back to the matching
+// ExposureRule, the selected ExposureClass and then to the same annotation on
+// the Namespace. Controller flags apply when all are empty.
+func (r *ServiceReconciler) setting(s serviceSettings, key string) string {
+	if value := r.annotation(s.svc, key); value != "" {
+		return value
+	}
+	if value := ruleSettings(s.rule)[key]; value != "" {
+		return value
+	}
+	if value := classSettings(s.class)[key]; value != "" {
+		return value
+	}
+	if slices.Contains(namespaceDefaultKeys, key) {
+		return r.annotation(s.ns, key)
+	}
+	return ""
+}
+
+// explicitTarget returns the listener set by the Service, its ExposureRule,
+// its ExposureClass or its Namespace. Empty fields are left to listener selection and the
+// controller defaults.
+func (r *ServiceReconciler) explicitTarget(ctx context.Context, svc *corev1.Service) (gatewayTarget, error) {
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return gatewayTarget{}, err
+	}
+	return gatewayTarget{
+		Name:        r.setting(s, AnnotationGateway),
+		Namespace:   r.setting(s, AnnotationGatewayNamespace),
+		SectionName: r.setting(s, AnnotationSectionName),
+	}, nil
+}
+
+// skipsReferenceGrant reports whether the Service, its ExposureRule or its
+// Namespace opted out of the generated ReferenceGrant.
+func (r *ServiceReconciler) skipsReferenceGrant(ctx context.Context, svc *corev1.Service) (bool, error) {
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return false, err
+	}
+	return r.setting(s, AnnotationSkipReferenceGrant) == "true", nil
+}
+
+// requestsForNamespaceDefaults requeues the exposed Services in Namespaces
+// whose gateway defaults point at gw. The gateway index only covers Service
+// annotations.
+func (r *ServiceReconciler) requestsForNamespaceDefaults(ctx context.Context, gw client.Object) []reconcile.Request {
+	namespaces := &corev1.NamespaceList{}
+	if err := r.List(ctx, namespaces); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range namespaces.Items {
+		ns := &namespaces.Items[i]
+		if r.annotation(ns, AnnotationGateway) == "" && r.annotation(ns, AnnotationGatewayNamespace) == "" {
+			continue
+		}
+		name := valueOrDefault(r.annotation(ns, AnnotationGateway), r.config().DefaultGateway)
+		namespace := valueOrDefault(r.annotation(ns, AnnotationGatewayNamespace), r.config().DefaultGatewayNamespace)
+		if namespace == gw.GetNamespace() && (name == gw.GetName() || r.annotation(ns, AnnotationGateway) == "") {
+			requests = append(requests, r.requestsForNamespaceServices(ctx, ns)...)
+		}
+	}
+	return requests
+}
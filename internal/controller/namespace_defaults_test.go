@@ -0,0 +1,109 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+var _ = Describe("Namespace defaults", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When a Namespace sets gateway and hostname defaults", func() {
+		It("should apply them to Services without annotations and follow changes", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Namespace defaulting the gateway, section, hostname suffix and grant
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "tenant-defaults",
+					Annotations: map[string]string{
+						"httproute.controller/gateway":              "tenant-gateway",
+						"httproute.controller/gateway-namespace":    "custom-ns",
+						"httproute.controller/section-name":         "http",
+						"httproute.controller/hostname-suffix":      "tenant.example.com",
+						"httproute.controller/skip-reference-grant": "true",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ns)).Should(Succeed())
+
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-ns-defaults",
+					Namespace: "tenant-defaults",
+					Annotations: map[string]string{
+						"httproute.controller/expose": "true",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+
+			// ACT: Create the Service
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: HTTPRoute uses the Namespace defaults
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{
+				Name:      routeName("tenant-defaults", "test-svc-ns-defaults"),
+				Namespace: "custom-ns",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, routeKey, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("test-svc-ns-defaults.tenant.example.com")))
+			Expect(route.Spec.ParentRefs).To(HaveLen(1))
+			Expect(string(route.Spec.ParentRefs[0].Name)).To(Equal("tenant-gateway"))
+			Expect(string(*route.Spec.ParentRefs[0].SectionName)).To(Equal("http"))
+
+			// ASSERT: No ReferenceGrant is generated
+			Consistently(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      referenceGrantName("tenant-defaults", "test-svc-ns-defaults"),
+					Namespace: "tenant-defaults",
+				}, &gatewayv1beta1.ReferenceGrant{})
+				return errors.IsNotFound(err)
+			}, time.Second, interval).Should(BeTrue())
+
+			// ACT: Change the Namespace section default
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ns), ns)).Should(Succeed())
+			ns.Annotations["httproute.controller/section-name"] = "https"
+			Expect(k8sClient.Update(ctx, ns)).Should(Succeed())
+
+			// ASSERT: HTTPRoute follows the new default
+			Eventually(func() string {
+				if err := k8sClient.Get(ctx, routeKey, route); err != nil || len(route.Spec.ParentRefs) == 0 {
+					return ""
+				}
+				return string(*route.Spec.ParentRefs[0].SectionName)
+			}, timeout, interval).Should(Equal("https"))
+		})
+	})
+})
@@ -0,0 +1,167 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// generatedNameHashLength is the number of hex characters of the name hash
+const generatedNameHashLength = 8
+
+// routeName returns the HTTPRoute name for a Service. The hash of
+// namespace/name keeps a-b/c and a/b-c apart in the gateway namespace.
+func routeName(namespace, name string) string {
+	return generatedName(namespace+"-"+name, "httproute/"+namespace+"/"+name)
+}
+
+// referenceGrantName returns the ReferenceGrant name for a Service. The hash
+// suffix avoids clashing with user-created grants.
+func referenceGrantName(namespace, name string) string {
+	return generatedName(name+"-backend", "referencegrant/"+namespace+"/"+name)
+}
+
+// exposedRouteName returns the HTTPRoute name for an ExposedService. The hash
+// key differs from routeName so it never collides with a Service of the same name.
+func exposedRouteName(namespace, name string) string {
+	return generatedName(namespace+"-"+name, "exposedservice/"+namespace+"/"+name)
+}
+
+// exposedReferenceGrantName returns the ReferenceGrant name for an ExposedService.
+func exposedReferenceGrantName(namespace, name string) string {
+	return generatedName(name+"-backend", "exposedservice-referencegrant/"+namespace+"/"+name)
+}
+
+// generatedName appends a short hash of key to base, truncating base so the
+// result is a valid DNS label. Names stay usable as label values.
+func generatedName(base, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])[:generatedNameHashLength]
+
+	maxBase := validation.DNS1123LabelMaxLength - len(hash) - 1
+	if len(base) > maxBase {
+		base = strings.TrimRight(base[:maxBase], "-")
+	}
+	return base + "-" + hash
+}
+
+// legacyRouteName is the HTTPRoute name used before collision-safe naming.
+func legacyRouteName(svc *corev1.Service) string {
+	return fmt.Sprintf("%s-%s", svc.Namespace, svc.Name)
+}
+
+// legacyReferenceGrantName is the ReferenceGrant name used before collision-safe naming.
+func legacyReferenceGrantName(svc *corev1.Service) string {
+	return fmt.Sprintf("%s-backend", svc.Name)
+}
+
+// legacyFieldManagers are the field managers previous controller versions
+// wrote generated resources with: FieldManager, and the binary name client-go
+// defaults to when no field manager is set.
+var legacyFieldManagers = []string{FieldManager, "manager"}
+
+// writtenByController reports whether a managedFields entry of obj belongs to
+// the controller, which tells generated legacy resources from hand-written ones.
+func writtenByController(obj client.Object) bool {
+	for _, entry := range obj.GetManagedFields() {
+		if slices.Contains(legacyFieldManagers, entry.Manager) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownsLegacyResource reports whether the controller may delete the
+// legacy-named obj: checkOwnership accepts it for svc, or the controller wrote
+// it before ownership labels existed.
+func (r *ServiceReconciler) ownsLegacyResource(obj client.Object, svc *corev1.Service, kind string) bool {
+	return writtenByController(obj) || r.checkOwnership(obj, svc, kind) == nil
+}
+
+// isLegacyRouteFor reports whether route is a legacy-named HTTPRoute generated
+// for svc: it has no source labels, its only backend is the Service and the
+// controller owns it.
+func (r *ServiceReconciler) isLegacyRouteFor(route *gatewayv1.HTTPRoute, svc *corev1.Service) bool {
+	if route.Name != legacyRouteName(svc) || route.Labels[LabelServiceName] != "" {
+		return false
+	}
+	if !r.ownsLegacyResource(route, svc, "HTTPRoute") {
+		return false
+	}
+	if len(route.Spec.Rules) != 1 || len(route.Spec.Rules[0].BackendRefs) != 1 {
+		return false
+	}
+	ref := route.Spec.Rules[0].BackendRefs[0]
+	return string(ref.Name) == svc.Name && ref.Namespace != nil && string(*ref.Namespace) == svc.Namespace
+}
+
+// deleteLegacyResources removes the legacy-named HTTPRoutes and ReferenceGrant
+// generated for svc and returns the namespaced names of the deleted routes.
+// Previous versions always added the finalizer, so Services without it never
+// had legacy resources.
+func (r *ServiceReconciler) deleteLegacyResources(ctx context.Context, svc *corev1.Service) ([]string, error) {
+	var deleted []string
+	if !r.hasFinalizer(svc) {
+		return deleted, nil
+	}
+	for _, gatewayNamespace := range r.candidateGatewayNamespaces(r.annotation(svc, AnnotationGatewayNamespace)) {
+		route := &gatewayv1.HTTPRoute{}
+		err := r.Get(ctx, types.NamespacedName{Name: legacyRouteName(svc), Namespace: gatewayNamespace}, route)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		if !r.isLegacyRouteFor(route, svc) {
+			continue
+		}
+		if err := r.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted = append(deleted, gatewayNamespace+"/"+route.Name)
+	}
+
+	grant := &gatewayv1beta1.ReferenceGrant{}
+	err := r.Get(ctx, types.NamespacedName{Name: legacyReferenceGrantName(svc), Namespace: svc.Namespace}, grant)
+	if errors.IsNotFound(err) {
+		return deleted, nil
+	}
+	if err != nil {
+		return deleted, err
+	}
+	if !r.ownsLegacyResource(grant, svc, "ReferenceGrant") {
+		return deleted, nil
+	}
+	if err := r.Delete(ctx, grant); err != nil && !errors.IsNotFound(err) {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// migrateLegacyResources replaces legacy-named resources once the
+// collision-safe ones exist, so traffic keeps flowing during the rename.
+func (r *ServiceReconciler) migrateLegacyResources(ctx context.Context, svc *corev1.Service) error {
+	deleted, err := r.deleteLegacyResources(ctx, svc)
+	for _, route := range deleted {
+		r.recordEvent(svc, corev1.EventTypeNormal, "HTTPRouteMigrated", fmt.Sprintf("Replaced legacy HTTPRoute %s", route))
+	}
+	return err
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Generated resource names", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When naming generated resources", func() {
+		It("should keep Services with the same joined name apart", func() {
+			Expect(routeName("a-b", "c")).NotTo(Equal(routeName("a", "b-c")))
+			Expect(routeName("a-b", "c")).To(Equal(routeName("a-b", "c")))
+		})
+
+		It("should produce valid DNS labels for long names", func() {
+			name := routeName(strings.Repeat("n", 63), strings.Repeat("s", 63))
+			Expect(validation.IsDNS1123Label(name)).To(BeEmpty())
+
+			grant := referenceGrantName("default", strings.Repeat("s", 63))
+			Expect(validation.IsDNS1123Label(grant)).To(BeEmpty())
+		})
+	})
+
+	Context("When deciding whether a legacy resource may be deleted", func() {
+		It("should require the controller's field manager or an explicit adopt", func() {
+			r := &ServiceReconciler{}
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+			route := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "default-web"}}
+			Expect(r.ownsLegacyResource(route, svc, "HTTPRoute")).To(BeFalse())
+
+			route.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: FieldManager, Operation: "Update"}}
+			Expect(r.ownsLegacyResource(route, svc, "HTTPRoute")).To(BeTrue())
+
+			route.ManagedFields = nil
+			svc.Annotations = map[string]string{"httproute.controller/adopt": "true"}
+			Expect(r.ownsLegacyResource(route, svc, "HTTPRoute")).To(BeTrue())
+		})
+	})
+
+	Context("When a legacy-named HTTPRoute exists", func() {
+		It("should replace it with the collision-safe HTTPRoute", func() {
+			ctx := context.Background()
+
+			// ARRANGE: HTTPRoute created by a previous controller version
+			gatewayNamespace := gatewayv1.Namespace("envoy-gateway-system")
+			serviceNamespace := gatewayv1.Namespace("default")
+			legacy := &gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default-test-svc-legacy",
+					Namespace: "envoy-gateway-system",
+				},
+				Spec: gatewayv1.HTTPRouteSpec{
+					CommonRouteSpec: gatewayv1.CommonRouteSpec{
+						ParentRefs: []gatewayv1.ParentReference{
+							{Name: "test-gateway", Namespace: &gatewayNamespace},
+						},
+					},
+					Hostnames: []gatewayv1.Hostname{"legacy.example.com"},
+					Rules: []gatewayv1.HTTPRouteRule{
+						{
+							BackendRefs: []gatewayv1.HTTPBackendRef{
+								{
+									BackendRef: gatewayv1.BackendRef{
+										BackendObjectReference: gatewayv1.BackendObjectReference{
+											Name:      "test-svc-legacy",
+											Namespace: &serviceNamespace,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, legacy, client.FieldOwner(FieldManager))).Should(Succeed())
+
+			// ACT: Expose the Service
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-svc-legacy",
+					Namespace:  "default",
+					Finalizers: []string{FinalizerHTTPRoute},
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "legacy.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: Collision-safe HTTPRoute carries the source labels
+			route := &gatewayv1.HTTPRoute{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      routeName("default", "test-svc-legacy"),
+					Namespace: "envoy-gateway-system",
+				}, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(route.Labels).To(HaveKeyWithValue(LabelServiceNamespace, "default"))
+			Expect(route.Labels).To(HaveKeyWithValue(LabelServiceName, "test-svc-legacy"))
+
+			// ASSERT: Legacy HTTPRoute is removed
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, types.NamespacedName{
+					Name:      "default-test-svc-legacy",
+					Namespace: "envoy-gateway-system",
+				}, &gatewayv1.HTTPRoute{})
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When a hand-written HTTPRoute has the legacy name", func() {
+		It("should leave it alone", func() {
+			ctx := context.Background()
+
+			// ARRANGE: HTTPRoute written by someone else for an unexposed Service
+			serviceNamespace := gatewayv1.Namespace("default")
+			handWritten := &gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default-test-svc-hand-written",
+					Namespace: "envoy-gateway-system",
+				},
+				Spec: gatewayv1.HTTPRouteSpec{
+					Rules: []gatewayv1.HTTPRouteRule{{
+						BackendRefs: []gatewayv1.HTTPBackendRef{{
+							BackendRef: gatewayv1.BackendRef{
+								BackendObjectReference: gatewayv1.BackendObjectReference{
+									Name:      "test-svc-hand-written",
+									Namespace: &serviceNamespace,
+								},
+							},
+						}},
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, handWritten, client.FieldOwner("kubectl"))).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, handWritten) }()
+
+			// ACT: Create the Service without exposing it
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-hand-written",
+					Namespace: "default",
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt(8080)}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: The HTTPRoute survives reconciles
+			Consistently(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(handWritten), &gatewayv1.HTTPRoute{})
+			}, time.Second*2, interval).Should(Succeed())
+		})
+	})
+})
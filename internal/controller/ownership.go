@@ -0,0 +1,315 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+// Labels recording the source Service on generated resources
+const (
+	LabelServiceNamespace = AnnotationPrefix + "/service-namespace"
+	LabelServiceName      = AnnotationPrefix + "/service-name"
+	LabelServiceUID       = AnnotationPrefix + "/service-uid"
+	LabelManagedBy        = "app.kubernetes.io/managed-by"
+	// ManagedByValue marks resources generated by this controller
+	ManagedByValue = "httproute-controller"
+)
+
+const (
+	// AnnotationAdopt lets the controller take over pre-existing resources
+	// that carry the generated names but not its ownership labels
+	AnnotationAdopt = AnnotationPrefix + "/adopt"
+	// ReasonResourceConflict reports a generated name taken by an unmanaged resource
+	ReasonResourceConflict = "ResourceConflict"
+)
+
+// resourceConflictRequeue is how often a Service blocked by an unmanaged resource is retried
+const resourceConflictRequeue = time.Minute
+
+// DefaultOrphanSweepInterval is how often generated resources are checked for a missing Service
+const DefaultOrphanSweepInterval = 10 * time.Minute
+
+// serviceLabels returns the labels recording the source Service.
+func serviceLabels(svc *corev1.Service) map[string]string {
+	return map[string]string{
+		LabelManagedBy:        ManagedByValue,
+		LabelServiceNamespace: svc.Namespace,
+		LabelServiceName:      svc.Name,
+		LabelServiceUID:       string(svc.UID),
+	}
+}
+
+// ownershipConflictError reports an existing resource the controller refuses to modify
+type ownershipConflictError struct {
+	kind  string
+	key   types.NamespacedName
+	adopt string
+	// source is the kind of the resource to annotate (empty means Service)
+	source string
+}
+
+func (e *ownershipConflictError) Error() string {
+	return fmt.Sprintf("%s %s is not managed by %s; annotate the %s with %s: \"true\" to adopt it",
+		e.kind, e.key, ManagedByValue, valueOrDefault(e.source, "Service"), e.adopt)
+}
+
+// checkOwnership returns an ownershipConflictError unless existing was
+// generated for svc or svc explicitly adopts it.
+func (r *ServiceReconciler) checkOwnership(existing client.Object, svc *corev1.Service, kind string) error {
+	labels := existing.GetLabels()
+	if labels[LabelManagedBy] == ManagedByValue &&
+		labels[LabelServiceNamespace] == svc.Namespace && labels[LabelServiceName] == svc.Name {
+		return nil
+	}
+	// Labels may have been removed from a resource the controller applied
+	if appliedBy(existing) {
+		return nil
+	}
+	// ReferenceGrants created before ownership labels are controlled by the Service
+	if metav1.IsControlledBy(existing, svc) || r.annotation(svc, AnnotationAdopt) == "true" {
+		return nil
+	}
+	return &ownershipConflictError{kind: kind, key: client.ObjectKeyFromObject(existing), adopt: r.key(AnnotationAdopt)}
+}
+
+// isExposed reports whether the Service asks for an HTTPRoute, directly or
+// through an ExposureRule or Namespace auto-expose as recorded by resolveExposure.
+func (r *ServiceReconciler) isExposed(svc *corev1.Service) bool {
+	switch r.annotation(svc, AnnotationExpose) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return r.annotation(svc, AnnotationExposureRule) != "" || r.annotation(svc, AnnotationAutoExposed) == "true"
+}
+
+// syncFinalizer adds or removes the class finalizer, replacing it under legacy
+// prefixes and updating the Service only on change.
+func (r *ServiceReconciler) syncFinalizer(ctx context.Context, svc *corev1.Service, want bool) error {
+	changed := false
+	for _, legacy := range r.legacyKeys(r.classFinalizer()) {
+		changed = controllerutil.RemoveFinalizer(svc, legacy) || changed
+	}
+	if want {
+		changed = controllerutil.AddFinalizer(svc, r.finalizer()) || changed
+	} else {
+		changed = controllerutil.RemoveFinalizer(svc, r.finalizer()) || changed
+	}
+	if !changed {
+		return nil
+	}
+	return r.Update(ctx, svc)
+}
+
+// generatedResources lists the HTTPRoutes and ReferenceGrants generated by the
+// controller class for Services and ExposedServices, optionally narrowed by
+// additional label selectors.
+func (r *ServiceReconciler) generatedResources(
+	ctx context.Context, selector client.MatchingLabels,
+) ([]client.Object, error) {
+	set := labels.Set{LabelManagedBy: ManagedByValue}
+	for k, v := range selector {
+		set[k] = v
+	}
+	matching := client.MatchingLabelsSelector{
+		Selector: labels.SelectorFromSet(set).Add(r.classRequirement()),
+	}
+
+	routes := &gatewayv1.HTTPRouteList{}
+	if err := r.List(ctx, routes, matching); err != nil {
+		return nil, err
+	}
+	grants := &gatewayv1beta1.ReferenceGrantList{}
+	if err := r.List(ctx, grants, matching); err != nil {
+		return nil, err
+	}
+
+	objects := make([]client.Object, 0, len(routes.Items)+len(grants.Items))
+	for i := range routes.Items {
+		objects = append(objects, &routes.Items[i])
+	}
+	for i := range grants.Items {
+		objects = append(objects, &grants.Items[i])
+	}
+	return objects, nil
+}
+
+// deleteGeneratedResources deletes everything generated for the Service key.
+// It covers Services deleted without the finalizer.
+func (r *ServiceReconciler) deleteGeneratedResources(ctx context.Context, key types.NamespacedName) error {
+	svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	_, err := r.pruneGeneratedResources(ctx, svc, nil)
+	return err
+}
+
+// pruneGeneratedResources deletes the resources generated for svc that keep
+// rejects and returns the deleted ones. Ownership labels record where resources
+// were actually created, so this also finds them after annotations moved them.
+func (r *ServiceReconciler) pruneGeneratedResources(
+	ctx context.Context, svc *corev1.Service, keep func(client.Object) bool,
+) ([]client.Object, error) {
+	objects, err := r.generatedResources(ctx, client.MatchingLabels{
+		LabelServiceNamespace: svc.Namespace,
+		LabelServiceName:      svc.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var deleted []client.Object
+	for _, obj := range objects {
+		if keep != nil && keep(obj) {
+			continue
+		}
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return deleted, err
+		}
+		deleted = append(deleted, obj)
+	}
+	return deleted, nil
+}
+
+// recordDeleted emits an event for every deleted generated resource.
+func (r *ServiceReconciler) recordDeleted(source client.Object, deleted []client.Object) {
+	for _, obj := range deleted {
+		key := client.ObjectKeyFromObject(obj).String()
+		switch obj.(type) {
+		case *gatewayv1.HTTPRoute:
+			r.recordEvent(source, corev1.EventTypeNormal, "HTTPRouteDeleted", key)
+		case *gatewayv1beta1.ReferenceGrant:
+			r.recordEvent(source, corev1.EventTypeNormal, "ReferenceGrantDeleted", key)
+		}
+	}
+}
+
+// isOrphan reports whether a generated resource outlived its Service: the
+// Service is gone, was recreated, stopped being exposed, or is being deleted
+// without the finalizer that would clean up after it.
+func (r *ServiceReconciler) isOrphan(ctx context.Context, obj client.Object) (bool, error) {
+	labels := obj.GetLabels()
+	if labels[LabelExposedServiceName] != "" {
+		return r.isExposedOrphan(ctx, obj)
+	}
+	if labels[LabelServiceName] == "" {
+		return false, nil
+	}
+	svc := &corev1.Service{}
+	key := types.NamespacedName{Namespace: labels[LabelServiceNamespace], Name: labels[LabelServiceName]}
+	// Services outside the managed namespaces may belong to another controller instance
+	if managed, err := r.managesNamespace(ctx, key.Namespace); err != nil || !managed {
+		return false, err
+	}
+	if err := r.Get(ctx, key, svc); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if uid := labels[LabelServiceUID]; uid != "" && uid != string(svc.UID) {
+		return true, nil
+	}
+	if !svc.DeletionTimestamp.IsZero() {
+		return !r.hasFinalizer(svc), nil
+	}
+	return !r.isExposed(svc) || !r.matchesClass(svc), nil
+}
+
+// isExposedOrphan reports whether a resource generated for an ExposedService
+// outlived it: the ExposedService is gone or is being deleted without the
+// finalizer that would clean up after it.
+func (r *ServiceReconciler) isExposedOrphan(ctx context.Context, obj client.Object) (bool, error) {
+	labels := obj.GetLabels()
+	es := &httproutev1alpha1.ExposedService{}
+	key := types.NamespacedName{Namespace: labels[LabelExposedServiceNamespace], Name: labels[LabelExposedServiceName]}
+	if managed, err := r.managesNamespace(ctx, key.Namespace); err != nil || !managed {
+		return false, err
+	}
+	if err := r.Get(ctx, key, es); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return !es.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(es, r.finalizer()), nil
+}
+
+// sweepOrphans deletes generated resources whose Service or ExposedService no
+// longer needs them.
+func (r *ServiceReconciler) sweepOrphans(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	objects, err := r.generatedResources(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		orphan, err := r.isOrphan(ctx, obj)
+		if err != nil {
+			return err
+		}
+		if !orphan {
+			continue
+		}
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		labels := obj.GetLabels()
+		owner := "Service " + labels[LabelServiceNamespace] + "/" + labels[LabelServiceName]
+		if labels[LabelExposedServiceName] != "" {
+			owner = "ExposedService " + labels[LabelExposedServiceNamespace] + "/" + labels[LabelExposedServiceName]
+		}
+		log.Info("deleted orphaned resource",
+			"resource", fmt.Sprintf("%T %s", obj, client.ObjectKeyFromObject(obj)), "owner", owner)
+	}
+	return nil
+}
+
+// orphanSweeper runs sweepOrphans once the caches have synced and then every
+// OrphanSweepInterval. It only runs on the elected leader.
+func (r *ServiceReconciler) orphanSweeper() manager.Runnable {
+	return manager.RunnableFunc(func(ctx context.Context) error {
+		logger := log.FromContext(ctx).WithName("orphan-sweeper")
+		ctx = log.IntoContext(ctx, logger)
+
+		if err := r.sweepOrphans(ctx); err != nil {
+			logger.Error(err, "orphan sweep failed")
+		}
+		if r.config().OrphanSweepInterval <= 0 {
+			return nil
+		}
+
+		ticker := time.NewTicker(r.config().OrphanSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := r.sweepOrphans(ctx); err != nil {
+					logger.Error(err, "orphan sweep failed")
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,299 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+var _ = Describe("Ownership labels", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	newReconciler := func() *ServiceReconciler {
+		return &ServiceReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: Config{
+				DefaultGateway:          "test-gateway",
+				DefaultGatewayNamespace: "envoy-gateway-system",
+				DefaultSectionName:      "https",
+			},
+		}
+	}
+
+	// orphanRoute returns a generated HTTPRoute for a Service that may not exist
+	orphanRoute := func(serviceName string) *gatewayv1.HTTPRoute {
+		svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: "default", UID: "gone"}}
+		return &gatewayv1.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      routeName("default", serviceName),
+				Namespace: "envoy-gateway-system",
+				Labels:    serviceLabels(svc),
+			},
+			Spec: gatewayv1.HTTPRouteSpec{
+				Hostnames: []gatewayv1.Hostname{"orphan.example.com"},
+			},
+		}
+	}
+
+	Context("When exposing a Service", func() {
+		It("should label generated resources with the source Service", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Exposed Service
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-owned",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "owned.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: HTTPRoute carries the ownership labels
+			route := &gatewayv1.HTTPRoute{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      routeName("default", "test-svc-owned"),
+					Namespace: "envoy-gateway-system",
+				}, route)
+			}, timeout, interval).Should(Succeed())
+			Expect(route.Labels).To(HaveKeyWithValue(LabelManagedBy, ManagedByValue))
+			Expect(route.Labels).To(HaveKeyWithValue(LabelServiceUID, string(svc.UID)))
+		})
+	})
+
+	Context("When a generated HTTPRoute outlives its Service", func() {
+		It("should be deleted by the orphan sweep", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Labeled HTTPRoute without a Service
+			route := orphanRoute("test-svc-swept")
+			Expect(k8sClient.Create(ctx, route)).Should(Succeed())
+
+			// ACT: Sweep orphans
+			Expect(newReconciler().sweepOrphans(ctx)).Should(Succeed())
+
+			// ASSERT: HTTPRoute is deleted
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, route)
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "orphaned HTTPRoute should be deleted")
+		})
+
+		It("should be deleted by the orphan sweep when its ExposedService is gone", func() {
+			ctx := context.Background()
+
+			// ARRANGE: HTTPRoute labeled for an ExposedService that does not exist
+			r := newReconciler()
+			es := &httproutev1alpha1.ExposedService{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-exposed-swept", Namespace: "default"},
+			}
+			route := &gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      exposedRouteName("default", "test-exposed-swept"),
+					Namespace: "envoy-gateway-system",
+					Labels:    r.exposedServiceLabels(es),
+				},
+				Spec: gatewayv1.HTTPRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"exposed-orphan.example.com"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, route)).Should(Succeed())
+
+			// ACT: Sweep orphans
+			Expect(r.sweepOrphans(ctx)).Should(Succeed())
+
+			// ASSERT: HTTPRoute is deleted
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, route)
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "orphaned HTTPRoute should be deleted")
+		})
+
+		It("should be deleted when the Service deletion is reconciled", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Labeled HTTPRoute for a Service deleted without finalizer
+			route := orphanRoute("test-svc-no-finalizer")
+			Expect(k8sClient.Create(ctx, route)).Should(Succeed())
+
+			// ACT: Reconcile the missing Service
+			_, err := newReconciler().Reconcile(ctx, ctrl.Request{
+				NamespacedName: types.NamespacedName{Name: "test-svc-no-finalizer", Namespace: "default"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: HTTPRoute is deleted
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, route)
+			Expect(errors.IsNotFound(err)).To(BeTrue(), "HTTPRoute of deleted Service should be deleted")
+		})
+	})
+
+	Context("When the desired location of generated resources changes", func() {
+		It("should move the HTTPRoute and drop the skipped ReferenceGrant", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Exposed Service with route and grant
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-relocated",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "relocated.example.com",
+						"httproute.controller/gateway":  "test-gateway",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			oldRouteKey := types.NamespacedName{
+				Name:      routeName("default", "test-svc-relocated"),
+				Namespace: "envoy-gateway-system",
+			}
+			grantKey := types.NamespacedName{
+				Name:      referenceGrantName("default", "test-svc-relocated"),
+				Namespace: "default",
+			}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, grantKey, &gatewayv1beta1.ReferenceGrant{})
+			}, timeout, interval).Should(Succeed())
+			Expect(k8sClient.Get(ctx, oldRouteKey, &gatewayv1.HTTPRoute{})).Should(Succeed())
+
+			// ACT: Move to another gateway namespace and skip the ReferenceGrant
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, svc)).Should(Succeed())
+			svc.Annotations["httproute.controller/gateway-namespace"] = "custom-ns"
+			svc.Annotations["httproute.controller/skip-reference-grant"] = "true"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+
+			// ASSERT: HTTPRoute exists in the new namespace only
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Name:      routeName("default", "test-svc-relocated"),
+					Namespace: "custom-ns",
+				}, &gatewayv1.HTTPRoute{})
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, oldRouteKey, &gatewayv1.HTTPRoute{}))
+			}, timeout, interval).Should(BeTrue())
+
+			// ASSERT: ReferenceGrant is removed
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, grantKey, &gatewayv1beta1.ReferenceGrant{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When an unmanaged HTTPRoute holds the generated name", func() {
+		It("should refuse to modify it until the Service adopts it", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Hand-written HTTPRoute with the generated name
+			handmade := &gatewayv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      routeName("default", "test-svc-adopt"),
+					Namespace: "envoy-gateway-system",
+				},
+				Spec: gatewayv1.HTTPRouteSpec{
+					Hostnames: []gatewayv1.Hostname{"handmade.example.com"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, handmade)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, handmade) }()
+
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-adopt",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "adopted.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			// ASSERT: Service reports the conflict and the route is untouched
+			svcKey := types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}
+			Eventually(func() string {
+				current := &corev1.Service{}
+				if err := k8sClient.Get(ctx, svcKey, current); err != nil {
+					return ""
+				}
+				cond := meta.FindStatusCondition(current.Status.Conditions, ConditionExposed)
+				if cond == nil {
+					return ""
+				}
+				return cond.Reason
+			}, timeout, interval).Should(Equal(ReasonResourceConflict))
+
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{Name: handmade.Name, Namespace: handmade.Namespace}
+			Expect(k8sClient.Get(ctx, routeKey, route)).Should(Succeed())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("handmade.example.com")))
+
+			// ACT: Adopt the route
+			Expect(k8sClient.Get(ctx, svcKey, svc)).Should(Succeed())
+			svc.Annotations["httproute.controller/adopt"] = "true"
+			Expect(k8sClient.Update(ctx, svc)).Should(Succeed())
+
+			// ASSERT: Route is taken over
+			Eventually(func() []gatewayv1.Hostname {
+				if err := k8sClient.Get(ctx, routeKey, route); err != nil {
+					return nil
+				}
+				return route.Spec.Hostnames
+			}, timeout, interval).Should(ConsistOf(gatewayv1.Hostname("adopted.example.com")))
+			Expect(route.Labels).To(HaveKeyWithValue(LabelManagedBy, ManagedByValue))
+		})
+	})
+})
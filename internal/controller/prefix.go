@@ -0,0 +1,107 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ValidateAnnotationPrefix checks that prefix can be used as the prefix of
+// annotation keys and finalizers.
+func ValidateAnnotationPrefix(prefix string) []string {
+	return validation.IsDNS1123Subdomain(prefix)
+}
+
+// key returns the Service or Namespace annotation key under the configured
+// prefix. Keys are declared under AnnotationPrefix, e.g. AnnotationExpose.
+func (r *ServiceReconciler) key(key string) string {
+	return r.prefix() + strings.TrimPrefix(key, AnnotationPrefix)
+}
+
+func (r *ServiceReconciler) prefix() string {
+	return valueOrDefault(r.config().AnnotationPrefix, AnnotationPrefix)
+}
+
+// legacyKeys returns key under every legacy prefix, in order of precedence.
+func (r *ServiceReconciler) legacyKeys(key string) []string {
+	keys := make([]string, 0, len(r.config().LegacyAnnotationPrefixes))
+	for _, prefix := range r.config().LegacyAnnotationPrefixes {
+		if prefix != r.prefix() {
+			keys = append(keys, prefix+strings.TrimPrefix(key, AnnotationPrefix))
+		}
+	}
+	return keys
+}
+
+// annotation returns the value of key on obj under the configured prefix,
+// falling back to the legacy prefixes.
+func (r *ServiceReconciler) annotation(obj client.Object, key string) string {
+	annotations := obj.GetAnnotations()
+	if value := annotations[r.key(key)]; value != "" {
+		return value
+	}
+	for _, legacy := range r.legacyKeys(key) {
+		if value := annotations[legacy]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// patchAnnotations sets the annotations the controller maintains on obj,
+// removing empty values and copies under legacy prefixes. The object is only
+// patched on change.
+func (r *ServiceReconciler) patchAnnotations(ctx context.Context, obj client.Object, values map[string]string) error {
+	original := obj.DeepCopyObject().(client.Object)
+	annotations := obj.GetAnnotations()
+	changed := false
+	for key, value := range values {
+		for _, legacy := range r.legacyKeys(key) {
+			if _, ok := annotations[legacy]; ok {
+				delete(annotations, legacy)
+				changed = true
+			}
+		}
+		current, ok := annotations[r.key(key)]
+		switch {
+		case value == "" && ok:
+			delete(annotations, r.key(key))
+			changed = true
+		case value != "" && current != value:
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[r.key(key)] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	obj.SetAnnotations(annotations)
+	return r.Patch(ctx, obj, client.MergeFrom(original))
+}
+
+// hasFinalizer reports whether svc carries the class finalizer under the
+// configured or a legacy prefix.
+func (r *ServiceReconciler) hasFinalizer(svc *corev1.Service) bool {
+	if controllerutil.ContainsFinalizer(svc, r.finalizer()) {
+		return true
+	}
+	for _, legacy := range r.legacyKeys(r.classFinalizer()) {
+		if controllerutil.ContainsFinalizer(svc, legacy) {
+			return true
+		}
+	}
+	return false
+}
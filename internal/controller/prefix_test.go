@@ -0,0 +1,106 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Annotation prefix", func() {
+	prefixedReconciler := func() *ServiceReconciler {
+		return &ServiceReconciler{
+			Client: k8sClient,
+			Scheme: k8sClient.Scheme(),
+			Config: Config{
+				DefaultGateway:           "test-gateway",
+				DefaultGatewayNamespace:  "envoy-gateway-system",
+				DefaultSectionName:       "https",
+				ControllerClass:          "prefixed",
+				AnnotationPrefix:         "routing.acme.io",
+				LegacyAnnotationPrefixes: []string{"httproute.controller"},
+			},
+		}
+	}
+
+	Context("When reading annotations", func() {
+		It("should prefer the configured prefix over legacy prefixes", func() {
+			r := prefixedReconciler()
+			svc := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				"httproute.controller/hostname": "legacy.example.com",
+				"httproute.controller/port":     "8080",
+				"routing.acme.io/hostname":      "new.example.com",
+			}}}
+
+			Expect(r.key(AnnotationHostname)).To(Equal("routing.acme.io/hostname"))
+			Expect(r.annotation(svc, AnnotationHostname)).To(Equal("new.example.com"))
+			Expect(r.annotation(svc, AnnotationPort)).To(Equal("8080"))
+			Expect(r.annotation(svc, AnnotationGateway)).To(BeEmpty())
+		})
+	})
+
+	Context("When a Service carries a finalizer under a legacy prefix", func() {
+		It("should rewrite it to the configured prefix", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Service annotated with the new prefix and a legacy finalizer
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test-svc-prefixed",
+					Namespace:  "default",
+					Finalizers: []string{"httproute.controller/httproute-finalizer-prefixed"},
+					Annotations: map[string]string{
+						"routing.acme.io/expose":        "true",
+						"routing.acme.io/class":         "prefixed",
+						"routing.acme.io/hostname":      "prefixed.example.com",
+						"httproute.controller/hostname": "legacy.example.com",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() {
+				_ = k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)
+				svc.Finalizers = nil
+				_ = k8sClient.Update(ctx, svc)
+				_ = k8sClient.Delete(ctx, svc)
+			}()
+
+			// ACT: Reconcile with the prefixed controller
+			_, err := prefixedReconciler().Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(svc)})
+			Expect(err).NotTo(HaveOccurred())
+
+			// ASSERT: Route uses the hostname under the new prefix
+			route := &gatewayv1.HTTPRoute{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      routeName("default", "test-svc-prefixed"),
+				Namespace: "envoy-gateway-system",
+			}, route)).Should(Succeed())
+			Expect(route.Spec.Hostnames).To(ConsistOf(gatewayv1.Hostname("prefixed.example.com")))
+
+			// ASSERT: Finalizer is rewritten to the new prefix
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(svc), svc)).Should(Succeed())
+			Expect(svc.Finalizers).To(ConsistOf("routing.acme.io/httproute-finalizer-prefixed"))
+		})
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+// routeParams describes a generated HTTPRoute sending traffic to a single Service port
+type routeParams struct {
+	Name      string
+	Namespace string
+	Labels    map[string]string
+	Parents   []gatewayTarget
+	Hostnames []gatewayv1.Hostname
+	Service   types.NamespacedName
+	Port      int32
+	// Rules add matches, filters and timeouts; none means a single rule matching every request
+	Rules []httproutev1alpha1.ExposedServiceRule
+}
+
+// newHTTPRoute builds the HTTPRoute described by p. Parents without a section
+// name attach to every listener of the Gateway.
+func newHTTPRoute(p routeParams) *gatewayv1.HTTPRoute {
+	parentRefs := make([]gatewayv1.ParentReference, 0, len(p.Parents))
+	for _, parent := range p.Parents {
+		namespace := gatewayv1.Namespace(parent.Namespace)
+		ref := gatewayv1.ParentReference{
+			Name:      gatewayv1.ObjectName(parent.Name),
+			Namespace: &namespace,
+		}
+		if parent.SectionName != "" {
+			sectionName := gatewayv1.SectionName(parent.SectionName)
+			ref.SectionName = &sectionName
+		}
+		parentRefs = append(parentRefs, ref)
+	}
+
+	serviceNamespace := gatewayv1.Namespace(p.Service.Namespace)
+	port := gatewayv1.PortNumber(p.Port)
+	backendRefs := []gatewayv1.HTTPBackendRef{{
+		BackendRef: gatewayv1.BackendRef{
+			BackendObjectReference: gatewayv1.BackendObjectReference{
+				Name:      gatewayv1.ObjectName(p.Service.Name),
+				Namespace: &serviceNamespace,
+				Port:      &port,
+			},
+		},
+	}}
+	rules := []gatewayv1.HTTPRouteRule{{BackendRefs: backendRefs}}
+	if len(p.Rules) > 0 {
+		rules = make([]gatewayv1.HTTPRouteRule, 0, len(p.Rules))
+		for _, rule := range p.Rules {
+			rules = append(rules, gatewayv1.HTTPRouteRule{
+				Matches:     rule.Matches,
+				Filters:     rule.Filters,
+				Timeouts:    rule.Timeouts,
+				BackendRefs: backendRefs,
+			})
+		}
+	}
+
+	return &gatewayv1.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1.GroupVersion.String(),
+			Kind:       "HTTPRoute",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      p.Name,
+			Namespace: p.Namespace,
+			Labels:    p.Labels,
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs},
+			Hostnames:       p.Hostnames,
+			Rules:           rules,
+		},
+	}
+}
+
+// newReferenceGrant builds a ReferenceGrant in namespace letting HTTPRoutes in
+// routeNamespace reference the Service serviceName.
+func newReferenceGrant(
+	name, namespace string, labels map[string]string, routeNamespace, serviceName string,
+) *gatewayv1beta1.ReferenceGrant {
+	service := gatewayv1.ObjectName(serviceName)
+	return &gatewayv1beta1.ReferenceGrant{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayv1beta1.GroupVersion.String(),
+			Kind:       "ReferenceGrant",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: gatewayv1beta1.ReferenceGrantSpec{
+			From: []gatewayv1beta1.ReferenceGrantFrom{{
+				Group:     gatewayv1.GroupName,
+				Kind:      "HTTPRoute",
+				Namespace: gatewayv1.Namespace(routeNamespace),
+			}},
+			To: []gatewayv1beta1.ReferenceGrantTo{{
+				Group: "",
+				Kind:  "Service",
+				Name:  &service,
+			}},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// routeConditions pairs HTTPRoute parent condition types with the Service conditions mirroring them
+var routeConditions = []struct {
+	route   gatewayv1.RouteConditionType
+	service string
+}{
+	{route: gatewayv1.RouteConditionAccepted, service: ConditionHTTPRouteAccepted},
+	{route: gatewayv1.RouteConditionResolvedRefs, service: ConditionHTTPRouteResolvedRefs},
+}
+
+// syncRouteConditions copies the Accepted and ResolvedRefs conditions the
+// gateway controller reported for target onto the Service. Tenants usually
+// cannot read routes in the gateway namespace, so this is their only view.
+func (r *ServiceReconciler) syncRouteConditions(ctx context.Context, svc *corev1.Service, target gatewayTarget) error {
+	route := &gatewayv1.HTTPRoute{}
+	key := types.NamespacedName{Name: routeName(svc.Namespace, svc.Name), Namespace: target.Namespace}
+	if err := r.Get(ctx, key, route); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	var parentConditions []metav1.Condition
+	if parent := findRouteParent(route, target); parent != nil {
+		parentConditions = parent.Conditions
+	}
+	for _, mirror := range routeConditions {
+		cond := meta.FindStatusCondition(parentConditions, string(mirror.route))
+		if cond == nil {
+			if err := r.setCondition(ctx, svc, mirror.service, metav1.ConditionUnknown, ReasonPending,
+				"Waiting for the gateway controller to report HTTPRoute status"); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.setCondition(ctx, svc, mirror.service, cond.Status, cond.Reason, cond.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findRouteParent returns the route status reported for the target listener.
+func findRouteParent(route *gatewayv1.HTTPRoute, target gatewayTarget) *gatewayv1.RouteParentStatus {
+	for i := range route.Status.Parents {
+		ref := route.Status.Parents[i].ParentRef
+		namespace := route.Namespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		if string(ref.Name) != target.Name || namespace != target.Namespace {
+			continue
+		}
+		if ref.SectionName != nil && string(*ref.SectionName) != target.SectionName {
+			continue
+		}
+		return &route.Status.Parents[i]
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("HTTPRoute status", func() {
+	const (
+		timeout  = time.Second * 10
+		interval = time.Millisecond * 250
+	)
+
+	Context("When the gateway controller reports the route status", func() {
+		It("should mirror Accepted and ResolvedRefs onto the Service", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Exposed Service with a generated route
+			svc := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-svc-route-status",
+					Namespace: "default",
+					Annotations: map[string]string{
+						"httproute.controller/expose":   "true",
+						"httproute.controller/hostname": "status.example.com",
+						"httproute.controller/gateway":  "test-gateway",
+					},
+				},
+				Spec: corev1.ServiceSpec{
+					Ports: []corev1.ServicePort{
+						{
+							Port:       80,
+							TargetPort: intstr.FromInt(8080),
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+			defer func() { _ = k8sClient.Delete(ctx, svc) }()
+
+			condition := func(conditionType string) func() *metav1.Condition {
+				return func() *metav1.Condition {
+					current := &corev1.Service{}
+					if err := k8sClient.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}, current); err != nil {
+						return nil
+					}
+					return meta.FindStatusCondition(current.Status.Conditions, conditionType)
+				}
+			}
+
+			// ASSERT: Conditions are pending until the gateway reports
+			Eventually(condition(ConditionHTTPRouteAccepted), timeout, interval).Should(
+				HaveField("Reason", ReasonPending))
+
+			// ACT: Gateway controller rejects the route
+			route := &gatewayv1.HTTPRoute{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Name:      routeName("default", "test-svc-route-status"),
+				Namespace: "envoy-gateway-system",
+			}, route)).Should(Succeed())
+			gatewayNamespace := gatewayv1.Namespace("envoy-gateway-system")
+			sectionName := gatewayv1.SectionName("https")
+			now := metav1.Now()
+			route.Status.Parents = []gatewayv1.RouteParentStatus{{
+				ParentRef: gatewayv1.ParentReference{
+					Name:        "test-gateway",
+					Namespace:   &gatewayNamespace,
+					SectionName: &sectionName,
+				},
+				ControllerName: "example.com/gateway-controller",
+				Conditions: []metav1.Condition{
+					{
+						Type:               string(gatewayv1.RouteConditionAccepted),
+						Status:             metav1.ConditionFalse,
+						Reason:             string(gatewayv1.RouteReasonNotAllowedByListeners),
+						Message:            "listener does not allow routes from this namespace",
+						LastTransitionTime: now,
+					},
+					{
+						Type:               string(gatewayv1.RouteConditionResolvedRefs),
+						Status:             metav1.ConditionTrue,
+						Reason:             string(gatewayv1.RouteReasonResolvedRefs),
+						Message:            "references resolved",
+						LastTransitionTime: now,
+					},
+				},
+			}}
+			Expect(k8sClient.Status().Update(ctx, route)).Should(Succeed())
+
+			// ASSERT: Service mirrors the gateway's verdict
+			Eventually(condition(ConditionHTTPRouteAccepted), timeout, interval).Should(And(
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", string(gatewayv1.RouteReasonNotAllowedByListeners)),
+			))
+			Eventually(condition(ConditionHTTPRouteResolvedRefs), timeout, interval).Should(
+				HaveField("Status", metav1.ConditionTrue))
+		})
+	})
+})
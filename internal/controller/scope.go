@@ -0,0 +1,105 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
+)
+
+// CacheOptions restricts the manager cache when WatchNamespaces is set:
+// Services, ReferenceGrants, ExposureRules and ExposedServices are cached in
+// the watched namespaces, Gateways and HTTPRoutes in the gateway namespaces. Every cached
+// resource then only needs namespace-scoped Roles; Namespaces and
+// HostnamePolicies stay cluster-wide. NamespaceSelector does not restrict the
+// cache, it only filters which Services are reconciled.
+func (c Config) CacheOptions() cache.Options {
+	if len(c.WatchNamespaces) == 0 {
+		return cache.Options{}
+	}
+
+	watched := namespaceConfigs(c.WatchNamespaces)
+	gateways := namespaceConfigs(append([]string{c.DefaultGatewayNamespace}, c.GatewayNamespaces...))
+	all := namespaceConfigs(c.WatchNamespaces)
+	for ns := range gateways {
+		all[ns] = cache.Config{}
+	}
+	return cache.Options{
+		DefaultNamespaces: all,
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Service{}:                   {Namespaces: watched},
+			&gatewayv1beta1.ReferenceGrant{}:    {Namespaces: watched},
+			&httproutev1alpha1.ExposureRule{}:   {Namespaces: watched},
+			&httproutev1alpha1.ExposedService{}: {Namespaces: watched},
+			&gatewayv1.Gateway{}:                {Namespaces: gateways},
+			&gatewayv1.HTTPRoute{}:              {Namespaces: gateways},
+		},
+	}
+}
+
+func namespaceConfigs(namespaces []string) map[string]cache.Config {
+	configs := make(map[string]cache.Config, len(namespaces))
+	for _, ns := range namespaces {
+		configs[ns] = cache.Config{}
+	}
+	return configs
+}
+
+// watchesNamespace reports whether namespace is one of WatchNamespaces, which
+// are the only namespaces whose Services are cached when it is set.
+func (r *ServiceReconciler) watchesNamespace(namespace string) bool {
+	return len(r.config().WatchNamespaces) == 0 || slices.Contains(r.config().WatchNamespaces, namespace)
+}
+
+// managesNamespace reports whether Services in namespace are reconciled by
+// this controller according to WatchNamespaces and NamespaceSelector.
+func (r *ServiceReconciler) managesNamespace(ctx context.Context, namespace string) (bool, error) {
+	if !r.watchesNamespace(namespace) {
+		return false, nil
+	}
+	if r.config().NamespaceSelector == nil || r.config().NamespaceSelector.Empty() {
+		return true, nil
+	}
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return r.config().NamespaceSelector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// responsibleFor reports whether the controller reconciles svc: it has the
+// controller class and lives in a managed namespace.
+func (r *ServiceReconciler) responsibleFor(ctx context.Context, svc *corev1.Service) (bool, error) {
+	if !r.matchesClass(svc) {
+		return false, nil
+	}
+	return r.managesNamespace(ctx, svc.Namespace)
+}
+
+// release hands a Service back once the controller is no longer responsible
+// for it, e.g. after its class changed. Services it never exposed are left
+// alone, since another controller instance may manage them.
+func (r *ServiceReconciler) release(ctx context.Context, svc *corev1.Service) (ctrl.Result, error) {
+	if !r.hasFinalizer(svc) {
+		return ctrl.Result{}, nil
+	}
+	if err := r.removeCondition(ctx, svc, ConditionExposed); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.unexpose(ctx, svc)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2025 Piotr Zaniewski.
+
+Licensed under the MIT License. See LICENSE file in the project root for full license information.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+var _ = Describe("Namespace scope", func() {
+	Context("When watch namespaces are configured", func() {
+		It("should cache Services in the watched namespaces and routes in the gateway namespaces", func() {
+			cfg := Config{
+				DefaultGatewayNamespace: "envoy-gateway-system",
+				GatewayNamespaces:       []string{"custom-ns"},
+				WatchNamespaces:         []string{"team-a", "team-b"},
+			}
+
+			opts := cfg.CacheOptions()
+
+			var services, routes map[string]bool
+			for obj, byObject := range opts.ByObject {
+				namespaces := map[string]bool{}
+				for ns := range byObject.Namespaces {
+					namespaces[ns] = true
+				}
+				switch obj.(type) {
+				case *corev1.Service:
+					services = namespaces
+				case *gatewayv1.HTTPRoute:
+					routes = namespaces
+				}
+			}
+			Expect(services).To(Equal(map[string]bool{"team-a": true, "team-b": true}))
+			Expect(routes).To(Equal(map[string]bool{"envoy-gateway-system": true, "custom-ns": true}))
+			Expect(opts.DefaultNamespaces).To(HaveLen(4))
+		})
+
+		It("should leave the cache cluster-wide without watch namespaces", func() {
+			Expect(Config{DefaultGatewayNamespace: "envoy-gateway-system"}.CacheOptions().ByObject).To(BeEmpty())
+		})
+	})
+
+	Context("When a namespace selector is configured", func() {
+		It("should only manage namespaces with matching labels", func() {
+			ctx := context.Background()
+
+			// ARRANGE: Labeled namespace and a reconciler selecting it
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "scoped-payments",
+					Labels: map[string]string{"team": "payments"},
+				},
+			}
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).Should(Succeed())
+
+			reconciler := &ServiceReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Config: Config{
+					DefaultGateway:          "test-gateway",
+					DefaultGatewayNamespace: "envoy-gateway-system",
+					NamespaceSelector:       labels.SelectorFromSet(labels.Set{"team": "payments"}),
+				},
+			}
+
+			// ACT & ASSERT: Only the labeled namespace is managed
+			managed, err := reconciler.managesNamespace(ctx, "scoped-payments")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(managed).To(BeTrue())
+
+			managed, err = reconciler.managesNamespace(ctx, "default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(managed).To(BeFalse())
+
+			// ACT & ASSERT: Watch namespaces narrow the selection further
+			reconciler.Config.WatchNamespaces = []string{"team-a"}
+			managed, err = reconciler.managesNamespace(ctx, "scoped-payments")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(managed).To(BeFalse())
+		})
+	})
+})
@@ -9,22 +9,33 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	httproutev1alpha1 "github.com/Piotr1215/httproute-controller/api/v1alpha1"
 )
 
-// Fixed annotation prefix - not configurable
+// Annotation keys under the default prefix; --annotation-prefix replaces it at runtime
 const (
 	AnnotationPrefix             = "httproute.controller"
 	AnnotationExpose             = AnnotationPrefix + "/expose"
@@ -45,6 +56,50 @@ type Config struct {
 	DefaultGatewayNamespace string
 	// DefaultSectionName is the default gateway listener section name
 	DefaultSectionName string
+	// GatewayNamespaces lists namespaces whose Gateways are considered for
+	// automatic listener selection (empty means DefaultGatewayNamespace only)
+	GatewayNamespaces []string
+	// HostnameTemplate derives the hostname when the hostname annotation is absent
+	HostnameTemplate string
+	// ClusterName is exposed to hostname templates as {{.ClusterName}}
+	ClusterName string
+	// DisableFinalizer stops adding the finalizer to exposed Services;
+	// deleted Services are then cleaned up through ownership labels
+	DisableFinalizer bool
+	// OrphanSweepInterval is how often orphaned resources are garbage collected
+	// (zero sweeps only at startup)
+	OrphanSweepInterval time.Duration
+	// WatchNamespaces restricts the Services reconciled, and the cache, to these
+	// namespaces (empty means all namespaces)
+	WatchNamespaces []string
+	// NamespaceSelector restricts the Services reconciled to namespaces with
+	// matching labels (nil means all namespaces)
+	NamespaceSelector labels.Selector
+	// ControllerClass is the httproute.controller/class value this instance
+	// reconciles (empty means Services without a class)
+	ControllerClass string
+	// DefaultClass also reconciles Services without a class annotation
+	DefaultClass bool
+	// AnnotationPrefix replaces the default prefix of Service and Namespace
+	// annotations and of the finalizer (empty means AnnotationPrefix)
+	AnnotationPrefix string
+	// LegacyAnnotationPrefixes are still read, after AnnotationPrefix, and
+	// finalizers under them are rewritten to AnnotationPrefix
+	LegacyAnnotationPrefixes []string
+	// AllowedHostnames and DeniedHostnames restrict the hostnames Services in
+	// any namespace may claim (set through ControllerConfig)
+	AllowedHostnames []string
+	DeniedHostnames  []string
+	// PublicGateways lists Gateways ("namespace/name") whose routes wait for
+	// approval before they are created
+	PublicGateways []string
+	// ApprovalWebhookURL is asked to approve routes to PublicGateways that are
+	// not approved by annotation (empty means annotation approval only)
+	ApprovalWebhookURL string
+	// ApprovalPolicy is the ValidatingAdmissionPolicy restricting who may set
+	// the approval annotation; annotation approvals are ignored unless it is
+	// installed and bound (empty ignores them all)
+	ApprovalPolicy string
 }
 
 // ServiceReconciler reconciles a Service object
@@ -53,234 +108,563 @@ type ServiceReconciler struct {
 	Scheme   *runtime.Scheme
 	Config   Config
 	Recorder record.EventRecorder
+	// Store holds the configuration reloaded from ControllerConfig; Config is
+	// used when it is nil
+	Store *ConfigStore
+	// ConfigChanges receives an event whenever Store changes
+	ConfigChanges <-chan event.GenericEvent
+}
+
+// config returns the configuration in effect.
+func (r *ServiceReconciler) config() Config {
+	if r.Store != nil {
+		return r.Store.Get()
+	}
+	return r.Config
 }
 
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=core,resources=services/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+// +kubebuilder:rbac:groups=httproute.controller,resources=hostnamepolicies,verbs=get;list;watch
 //nolint:lll
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 //nolint:lll
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch;create;update;patch;delete
 
 func (r *ServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := log.FromContext(ctx)
-
 	svc := &corev1.Service{}
 	if err := r.Get(ctx, req.NamespacedName, svc); err != nil {
 		if errors.IsNotFound(err) {
-			return ctrl.Result{}, nil
+			// Without the finalizer the Service may be gone before cleanup ran
+			return ctrl.Result{}, r.deleteGeneratedResources(ctx, req.NamespacedName)
 		}
 		return ctrl.Result{}, err
 	}
 
+	responsible, err := r.responsibleFor(ctx, svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !responsible {
+		return r.release(ctx, svc)
+	}
+
 	// Handle deletion
 	if !svc.DeletionTimestamp.IsZero() {
-		if controllerutil.ContainsFinalizer(svc, FinalizerHTTPRoute) {
+		if r.hasFinalizer(svc) {
 			if err := r.cleanupResources(ctx, svc); err != nil {
 				return ctrl.Result{}, err
 			}
-			controllerutil.RemoveFinalizer(svc, FinalizerHTTPRoute)
-			if err := r.Update(ctx, svc); err != nil {
+			if err := r.syncFinalizer(ctx, svc, false); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
 		return ctrl.Result{}, nil
 	}
 
+	exposed, err := r.resolveExposure(ctx, svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	// Not exposed - cleanup and remove finalizer
-	if svc.Annotations[AnnotationExpose] != "true" {
-		if err := r.cleanupResources(ctx, svc); err != nil {
+	if !exposed {
+		// Exposing the Service again starts a new expose-ttl
+		if err := r.removeCondition(ctx, svc, ConditionExposed, ConditionExposureStarted); err != nil {
 			return ctrl.Result{}, err
 		}
-		if controllerutil.ContainsFinalizer(svc, FinalizerHTTPRoute) {
-			controllerutil.RemoveFinalizer(svc, FinalizerHTTPRoute)
-			if err := r.Update(ctx, svc); err != nil {
-				return ctrl.Result{}, err
-			}
+		if err := r.patchAnnotations(ctx, svc, map[string]string{AnnotationGeneratedHostname: ""}); err != nil {
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.unexpose(ctx, svc)
+	}
+
+	return r.expose(ctx, svc)
+}
+
+// expose resolves the hostname and listener of an exposed Service, applies
+// its HTTPRoute and ReferenceGrant and reports the result on the Service.
+func (r *ServiceReconciler) expose(ctx context.Context, svc *corev1.Service) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	key := client.ObjectKeyFromObject(svc)
+
+	// Expired and out-of-window exposures are reconciled again when that changes
+	reason, message, requeue, err := r.checkExposureSchedule(ctx, svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if reason != "" {
+		return ctrl.Result{RequeueAfter: requeue}, r.blockExposure(ctx, svc, reason, message)
+	}
+
+	missing, err := r.checkExposureClass(ctx, svc)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if missing != "" {
+		return ctrl.Result{}, r.blockExposure(ctx, svc, ReasonExposureClassNotFound, missing)
 	}
 
-	hostname := svc.Annotations[AnnotationHostname]
+	hostname, generated, err := r.resolveHostname(ctx, svc)
+	if err != nil {
+		if !generated {
+			return ctrl.Result{}, err
+		}
+		// Template errors need a template fix, retrying will not help
+		return ctrl.Result{}, r.blockExposure(ctx, svc, ReasonInvalidHostname, err.Error())
+	}
 	if hostname == "" {
-		log.Error(nil, "hostname annotation required", "service", req.NamespacedName)
+		log.Error(nil, "hostname annotation or hostname template required", "service", key)
 		return ctrl.Result{}, nil
 	}
-
-	gatewayName := svc.Annotations[AnnotationGateway]
-	if gatewayName == "" {
-		gatewayName = r.Config.DefaultGateway
+	generatedHostname := ""
+	if generated {
+		generatedHostname = hostname
 	}
-	gatewayNamespace := svc.Annotations[AnnotationGatewayNamespace]
-	if gatewayNamespace == "" {
-		gatewayNamespace = r.Config.DefaultGatewayNamespace
+	if err := r.recordGeneratedHostname(ctx, svc, generatedHostname); err != nil {
+		return ctrl.Result{}, err
 	}
-	sectionName := svc.Annotations[AnnotationSectionName]
-	if sectionName == "" {
-		sectionName = r.Config.DefaultSectionName
+
+	reason, message, err = r.checkHostnameClaim(ctx, svc, hostname)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if reason != "" {
+		return ctrl.Result{}, r.blockExposure(ctx, svc, reason, message)
 	}
 
-	var port int32
-	if portStr := svc.Annotations[AnnotationPort]; portStr != "" {
-		_, _ = fmt.Sscanf(portStr, "%d", &port)
+	target, err := r.resolveTarget(ctx, svc, hostname)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
-	if port == 0 && len(svc.Spec.Ports) > 0 {
-		port = svc.Spec.Ports[0].Port
+	if err := r.checkGatewayTarget(ctx, svc, target); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	port, err := r.servicePort(ctx, svc)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 	if port == 0 {
-		log.Error(nil, "no port found", "service", req.NamespacedName)
+		log.Error(nil, "no port found", "service", key)
 		return ctrl.Result{}, nil
 	}
 
-	if err := r.reconcileHTTPRoute(ctx, svc, hostname, gatewayName, gatewayNamespace, sectionName, port); err != nil {
-		r.recordEvent(svc, corev1.EventTypeWarning, "HTTPRouteFailed", err.Error())
+	denied, err := r.checkExposurePolicies(ctx, svc, policyRoute{
+		Hostnames:        []string{hostname},
+		Gateway:          target.Name,
+		GatewayNamespace: target.Namespace,
+		SectionName:      target.SectionName,
+		Port:             port,
+	})
+	if err != nil {
 		return ctrl.Result{}, err
 	}
-	r.recordEvent(svc, corev1.EventTypeNormal, "HTTPRouteReconciled",
-		fmt.Sprintf("HTTPRoute %s-%s in %s", svc.Namespace, svc.Name, gatewayNamespace))
+	if denied != "" {
+		return ctrl.Result{}, r.blockExposure(ctx, svc, ReasonExposureDenied, denied)
+	}
 
-	if svc.Annotations[AnnotationSkipReferenceGrant] != "true" {
-		if err := r.reconcileReferenceGrant(ctx, svc, gatewayNamespace); err != nil {
-			r.recordEvent(svc, corev1.EventTypeWarning, "ReferenceGrantFailed", err.Error())
-			return ctrl.Result{}, err
+	pending, approved, err := r.checkApproval(ctx, svc, "Service", []gatewayTarget{target}, []string{hostname}, port)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if pending != "" {
+		return r.awaitApproval(ctx, svc, pending)
+	}
+
+	if err := r.applyResources(ctx, svc, hostname, target, port); err != nil {
+		if conflict, ok := err.(*ownershipConflictError); ok {
+			return r.refuseTakeover(ctx, svc, conflict)
 		}
+		return ctrl.Result{}, err
 	}
 
-	if !controllerutil.ContainsFinalizer(svc, FinalizerHTTPRoute) {
-		controllerutil.AddFinalizer(svc, FinalizerHTTPRoute)
-		if err := r.Update(ctx, svc); err != nil {
-			return ctrl.Result{}, err
+	// Unchanged once set, so the lastTransitionTime keeps the first route
+	if err := r.setCondition(ctx, svc, ConditionExposureStarted, metav1.ConditionTrue, ReasonRouteCreated,
+		exposureStartedMessage); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.setCondition(ctx, svc, ConditionExposed, metav1.ConditionTrue, ReasonReconciled,
+		attachedMessage(target)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.removeCondition(ctx, svc, ConditionPendingApproval); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.recordApproval(ctx, svc, approved); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.syncRouteConditions(ctx, svc, target); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.publishEndpoint(ctx, svc, hostname, target); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.syncFinalizer(ctx, svc, !r.config().DisableFinalizer); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Info("reconciled", "service", key, "hostname", hostname)
+	return ctrl.Result{RequeueAfter: recheckApproval(svc.UID, requeue)}, nil
+}
+
+// checkHostnameClaim returns the reason and message when the hostname policy
+// or an earlier claimant prevents svc from routing hostname.
+func (r *ServiceReconciler) checkHostnameClaim(
+	ctx context.Context, svc *corev1.Service, hostname string,
+) (string, string, error) {
+	// Refuse hostnames outside the namespace's hostname policy
+	violation, err := r.checkHostnamePolicy(ctx, svc, hostname)
+	if err != nil {
+		return "", "", err
+	}
+	if violation != "" {
+		return ReasonHostnameDenied, violation, nil
+	}
+
+	// Only the first claimant of a hostname gets a route
+	conflict, err := r.hostnameConflict(ctx, svc, hostname)
+	if err != nil {
+		return "", "", err
+	}
+	if conflict != "" {
+		return ReasonHostnameConflict, conflict, nil
+	}
+	return "", "", nil
+}
+
+// resolveTarget returns the listener the route attaches to: the one annotated
+// on the Service or its Namespace, otherwise the best matching one from
+// allowed Gateways, with gaps filled from the controller defaults.
+func (r *ServiceReconciler) resolveTarget(
+	ctx context.Context, svc *corev1.Service, hostname string,
+) (gatewayTarget, error) {
+	target, err := r.explicitTarget(ctx, svc)
+	if err != nil {
+		return gatewayTarget{}, err
+	}
+
+	// No explicit listener - pick the best matching one from allowed Gateways
+	if target.Name == "" && target.SectionName == "" {
+		selected, found, err := r.selectListener(ctx, target.Namespace, hostname)
+		if err != nil {
+			return gatewayTarget{}, err
+		}
+		if found {
+			target = selected
+			log.FromContext(ctx).V(1).Info("selected listener", "service", client.ObjectKeyFromObject(svc),
+				"gateway", target.Namespace+"/"+target.Name, "section", target.SectionName)
 		}
 	}
 
-	log.Info("reconciled", "service", req.NamespacedName, "hostname", hostname)
-	return ctrl.Result{}, nil
+	target.Name = valueOrDefault(target.Name, r.config().DefaultGateway)
+	target.Namespace = valueOrDefault(target.Namespace, r.config().DefaultGatewayNamespace)
+	target.SectionName = valueOrDefault(target.SectionName, r.config().DefaultSectionName)
+	return target, nil
 }
 
-func (r *ServiceReconciler) reconcileHTTPRoute(
-	ctx context.Context, svc *corev1.Service,
-	hostname, gatewayName, gatewayNamespace, sectionNameStr string, port int32,
+// servicePort returns the port set by the Service or its ExposureRule, or the
+// first Service port, or 0 when there is none.
+func (r *ServiceReconciler) servicePort(ctx context.Context, svc *corev1.Service) (int32, error) {
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return 0, err
+	}
+	var port int32
+	if portStr := r.setting(s, AnnotationPort); portStr != "" {
+		_, _ = fmt.Sscanf(portStr, "%d", &port)
+	}
+	if port == 0 && len(svc.Spec.Ports) > 0 {
+		port = svc.Spec.Ports[0].Port
+	}
+	return port, nil
+}
+
+// applyResources creates or updates the HTTPRoute and ReferenceGrant and
+// removes generated resources that no longer match them.
+func (r *ServiceReconciler) applyResources(
+	ctx context.Context, svc *corev1.Service, hostname string, target gatewayTarget, port int32,
 ) error {
-	routeName := fmt.Sprintf("%s-%s", svc.Namespace, svc.Name)
-	sectionName := gatewayv1.SectionName(sectionNameStr)
-
-	route := &gatewayv1.HTTPRoute{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      routeName,
-			Namespace: gatewayNamespace,
-		},
-		Spec: gatewayv1.HTTPRouteSpec{
-			CommonRouteSpec: gatewayv1.CommonRouteSpec{
-				ParentRefs: []gatewayv1.ParentReference{{
-					Name:        gatewayv1.ObjectName(gatewayName),
-					Namespace:   (*gatewayv1.Namespace)(&gatewayNamespace),
-					SectionName: &sectionName,
-				}},
-			},
-			Hostnames: []gatewayv1.Hostname{gatewayv1.Hostname(hostname)},
-			Rules: []gatewayv1.HTTPRouteRule{{
-				BackendRefs: []gatewayv1.HTTPBackendRef{{
-					BackendRef: gatewayv1.BackendRef{
-						BackendObjectReference: gatewayv1.BackendObjectReference{
-							Name:      gatewayv1.ObjectName(svc.Name),
-							Namespace: (*gatewayv1.Namespace)(&svc.Namespace),
-							Port:      (*gatewayv1.PortNumber)(&port),
-						},
-					},
-				}},
-			}},
-		},
+	result, err := r.reconcileHTTPRoute(ctx, svc, hostname, target, port)
+	if err != nil {
+		if _, ok := err.(*ownershipConflictError); !ok {
+			r.recordEvent(svc, corev1.EventTypeWarning, "HTTPRouteFailed", err.Error())
+		}
+		return err
+	}
+	// A missing route for a Service reporting this exact attachment was deleted behind our back
+	if result == applyCreated && attachedTo(svc, target) {
+		result = applyRecreated
+	}
+	r.recordApplied(svc, "HTTPRoute", fmt.Sprintf("%s/%s", target.Namespace, routeName(svc.Namespace, svc.Name)), result)
+
+	skipGrant, err := r.skipsReferenceGrant(ctx, svc)
+	if err != nil {
+		return err
+	}
+	if !skipGrant {
+		result, err := r.reconcileReferenceGrant(ctx, svc, target.Namespace)
+		if err != nil {
+			if _, ok := err.(*ownershipConflictError); !ok {
+				r.recordEvent(svc, corev1.EventTypeWarning, "ReferenceGrantFailed", err.Error())
+			}
+			return err
+		}
+		r.recordApplied(svc, "ReferenceGrant",
+			fmt.Sprintf("%s/%s", svc.Namespace, referenceGrantName(svc.Namespace, svc.Name)), result)
+	}
+
+	if err := r.pruneStaleResources(ctx, svc, target.Namespace, !skipGrant); err != nil {
+		return err
+	}
+	return r.migrateLegacyResources(ctx, svc)
+}
+
+// blockExposure reports why svc gets no route and removes its generated resources.
+func (r *ServiceReconciler) blockExposure(ctx context.Context, svc *corev1.Service, reason, message string) error {
+	if conditionChanges(svc.Status.Conditions, ConditionExposed, metav1.ConditionFalse, reason, message) {
+		r.recordEvent(svc, corev1.EventTypeWarning, reason, message)
+	}
+	if err := r.setCondition(ctx, svc, ConditionExposed, metav1.ConditionFalse, reason, message); err != nil {
+		return err
 	}
+	return r.unexpose(ctx, svc)
+}
+
+// reconcileHTTPRoute applies the HTTPRoute for svc and reports what changed.
+func (r *ServiceReconciler) reconcileHTTPRoute(
+	ctx context.Context, svc *corev1.Service, hostname string, target gatewayTarget, port int32,
+) (applyResult, error) {
+	s, err := r.settings(ctx, svc)
+	if err != nil {
+		return applyUnchanged, err
+	}
+	route := newHTTPRoute(routeParams{
+		Name:      routeName(svc.Namespace, svc.Name),
+		Namespace: target.Namespace,
+		Labels:    r.generatedLabels(svc),
+		Parents:   []gatewayTarget{target},
+		Hostnames: []gatewayv1.Hostname{gatewayv1.Hostname(hostname)},
+		Service:   client.ObjectKeyFromObject(svc),
+		Port:      port,
+		Rules:     classRules(s.class),
+	})
 
 	existing := &gatewayv1.HTTPRoute{}
-	err := r.Get(ctx, types.NamespacedName{Name: routeName, Namespace: gatewayNamespace}, existing)
+	err = r.Get(ctx, client.ObjectKeyFromObject(route), existing)
 	if errors.IsNotFound(err) {
-		return r.Create(ctx, route)
+		return r.applyGenerated(ctx, route, nil)
 	}
 	if err != nil {
-		return err
+		return applyUnchanged, err
+	}
+	if err := r.checkOwnership(existing, svc, "HTTPRoute"); err != nil {
+		return applyUnchanged, err
 	}
-	existing.Spec = route.Spec
-	return r.Update(ctx, existing)
+	return r.applyGenerated(ctx, route, existing)
 }
 
+// reconcileReferenceGrant applies the ReferenceGrant for svc and reports what changed.
 func (r *ServiceReconciler) reconcileReferenceGrant(
 	ctx context.Context, svc *corev1.Service, gatewayNamespace string,
-) error {
-	grantName := fmt.Sprintf("%s-backend", svc.Name)
-
-	grant := &gatewayv1beta1.ReferenceGrant{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      grantName,
-			Namespace: svc.Namespace,
-		},
-		Spec: gatewayv1beta1.ReferenceGrantSpec{
-			From: []gatewayv1beta1.ReferenceGrantFrom{{
-				Group:     gatewayv1.GroupName,
-				Kind:      "HTTPRoute",
-				Namespace: gatewayv1.Namespace(gatewayNamespace),
-			}},
-			To: []gatewayv1beta1.ReferenceGrantTo{{
-				Group: "",
-				Kind:  "Service",
-				Name:  (*gatewayv1.ObjectName)(&svc.Name),
-			}},
-		},
-	}
-
+) (applyResult, error) {
+	grant := newReferenceGrant(referenceGrantName(svc.Namespace, svc.Name), svc.Namespace,
+		r.generatedLabels(svc), gatewayNamespace, svc.Name)
 	if err := controllerutil.SetControllerReference(svc, grant, r.Scheme); err != nil {
-		return err
+		return applyUnchanged, err
 	}
 
 	existing := &gatewayv1beta1.ReferenceGrant{}
-	err := r.Get(ctx, types.NamespacedName{Name: grantName, Namespace: svc.Namespace}, existing)
+	err := r.Get(ctx, client.ObjectKeyFromObject(grant), existing)
 	if errors.IsNotFound(err) {
-		return r.Create(ctx, grant)
+		return r.applyGenerated(ctx, grant, nil)
 	}
 	if err != nil {
+		return applyUnchanged, err
+	}
+	if err := r.checkOwnership(existing, svc, "ReferenceGrant"); err != nil {
+		return applyUnchanged, err
+	}
+	return r.applyGenerated(ctx, grant, existing)
+}
+
+// refuseTakeover reports a generated name held by an unmanaged resource and
+// retries later, as the resource may be removed or adopted.
+func (r *ServiceReconciler) refuseTakeover(
+	ctx context.Context, svc *corev1.Service, conflict *ownershipConflictError,
+) (ctrl.Result, error) {
+	if conditionChanges(svc.Status.Conditions, ConditionExposed, metav1.ConditionFalse,
+		ReasonResourceConflict, conflict.Error()) {
+		r.recordEvent(svc, corev1.EventTypeWarning, ReasonResourceConflict, conflict.Error())
+	}
+	if err := r.setCondition(ctx, svc, ConditionExposed, metav1.ConditionFalse,
+		ReasonResourceConflict, conflict.Error()); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: resourceConflictRequeue}, nil
+}
+
+// unexpose removes generated resources and the finalizer from a Service that
+// should not have a route.
+func (r *ServiceReconciler) unexpose(ctx context.Context, svc *corev1.Service) error {
+	if err := r.cleanupResources(ctx, svc); err != nil {
+		return err
+	}
+	stale := []string{
+		ConditionGatewayResolved, ConditionHTTPRouteAccepted, ConditionHTTPRouteResolvedRefs, ConditionApproved,
+	}
+	// awaitApproval keeps PendingApproval, removing it first would flap the condition
+	if cond := meta.FindStatusCondition(svc.Status.Conditions, ConditionExposed); cond == nil ||
+		cond.Reason != ReasonPendingApproval {
+		stale = append(stale, ConditionPendingApproval)
+	}
+	if err := r.removeCondition(ctx, svc, stale...); err != nil {
 		return err
 	}
-	existing.Spec = grant.Spec
-	existing.OwnerReferences = grant.OwnerReferences
-	return r.Update(ctx, existing)
+	if err := r.recordEndpoint(ctx, svc, "", ""); err != nil {
+		return err
+	}
+	return r.syncFinalizer(ctx, svc, false)
 }
 
 func (r *ServiceReconciler) cleanupResources(ctx context.Context, svc *corev1.Service) error {
-	gatewayNamespace := svc.Annotations[AnnotationGatewayNamespace]
-	if gatewayNamespace == "" {
-		gatewayNamespace = r.Config.DefaultGatewayNamespace
+	deleted, err := r.pruneGeneratedResources(ctx, svc, nil)
+	r.recordDeleted(svc, deleted)
+	if err != nil {
+		return err
 	}
 
-	routeName := fmt.Sprintf("%s-%s", svc.Namespace, svc.Name)
-	route := &gatewayv1.HTTPRoute{}
-	if err := r.Get(ctx, types.NamespacedName{Name: routeName, Namespace: gatewayNamespace}, route); err == nil {
-		if err := r.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
-			return err
-		}
-		r.recordEvent(svc, corev1.EventTypeNormal, "HTTPRouteDeleted", routeName)
+	// Services exposed before collision-safe naming may still own legacy-named resources
+	legacy, err := r.deleteLegacyResources(ctx, svc)
+	for _, route := range legacy {
+		r.recordEvent(svc, corev1.EventTypeNormal, "HTTPRouteDeleted", route)
 	}
+	return err
+}
 
-	grantName := fmt.Sprintf("%s-backend", svc.Name)
-	grant := &gatewayv1beta1.ReferenceGrant{}
-	if err := r.Get(ctx, types.NamespacedName{Name: grantName, Namespace: svc.Namespace}, grant); err == nil {
-		if err := r.Delete(ctx, grant); err != nil && !errors.IsNotFound(err) {
-			return err
+// pruneStaleResources deletes generated resources that no longer match the
+// desired state, e.g. the HTTPRoute left in the previous gateway namespace or
+// the ReferenceGrant after skip-reference-grant was set.
+func (r *ServiceReconciler) pruneStaleResources(
+	ctx context.Context, svc *corev1.Service, gatewayNamespace string, wantGrant bool,
+) error {
+	deleted, err := r.pruneGeneratedResources(ctx, svc, func(obj client.Object) bool {
+		switch obj.(type) {
+		case *gatewayv1.HTTPRoute:
+			return obj.GetNamespace() == gatewayNamespace && obj.GetName() == routeName(svc.Namespace, svc.Name)
+		case *gatewayv1beta1.ReferenceGrant:
+			return wantGrant && obj.GetNamespace() == svc.Namespace &&
+				obj.GetName() == referenceGrantName(svc.Namespace, svc.Name)
 		}
-	}
-
-	return nil
+		return true
+	})
+	r.recordDeleted(svc, deleted)
+	return err
 }
 
-func (r *ServiceReconciler) recordEvent(svc *corev1.Service, eventType, reason, message string) {
+// recordEvent emits an event on the Service or ExposedService a resource is generated for.
+func (r *ServiceReconciler) recordEvent(source client.Object, eventType, reason, message string) {
 	if r.Recorder != nil {
-		r.Recorder.Event(svc, eventType, reason, message)
+		r.Recorder.Event(source, eventType, reason, message)
 	}
 }
 
 func (r *ServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Service{},
+		hostnameIndexKey, r.indexHostnameClaim); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Service{},
+		gatewayIndexKey, r.indexGatewayTarget); err != nil {
+		return err
+	}
+	if err := mgr.Add(r.orphanSweeper()); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Service{}).
 		Owns(&gatewayv1beta1.ReferenceGrant{}).
-		Named("service").
-		Complete(r)
+		// Generated routes live in gateway namespaces without owner references
+		Watches(&gatewayv1.HTTPRoute{}, handler.EnqueueRequestsFromMapFunc(r.requestForGeneratedResource)).
+		Watches(&corev1.Service{}, r.hostnameClaimHandler(false)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.requestsForNamespaceServices),
+			builder.WithPredicates(predicate.Or(predicate.AnnotationChangedPredicate{}, predicate.LabelChangedPredicate{}))).
+		Watches(&httproutev1alpha1.HostnamePolicy{}, handler.EnqueueRequestsFromMapFunc(r.requestsForExposedServices)).
+		Watches(&httproutev1alpha1.ExposureRule{}, handler.EnqueueRequestsFromMapFunc(r.requestsForExposureRule),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&httproutev1alpha1.ExposureClass{}, handler.EnqueueRequestsFromMapFunc(r.requestsForExposedServices),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&httproutev1alpha1.ExposurePolicy{}, handler.EnqueueRequestsFromMapFunc(r.requestsForExposedServices),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		// Listener changes bump the Gateway generation, address changes only touch its status
+		Watches(&gatewayv1.Gateway{}, handler.EnqueueRequestsFromMapFunc(r.requestsForGateway),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, gatewayAddressesChanged)))
+	if r.handlesResources() {
+		if err := mgr.GetFieldIndexer().IndexField(context.Background(), &httproutev1alpha1.ExposedService{},
+			hostnameIndexKey, indexExposedHostnameClaims); err != nil {
+			return err
+		}
+		// ExposedServices claim hostnames like Services do
+		b = b.Watches(&httproutev1alpha1.ExposedService{}, r.hostnameClaimHandler(false))
+	}
+	// Each controller reading the configuration gets its own copy of the changes
+	var serviceChanges, exposedChanges chan event.GenericEvent
+	if r.ConfigChanges != nil {
+		serviceChanges = make(chan event.GenericEvent)
+		outs := []chan<- event.GenericEvent{serviceChanges}
+		if r.handlesResources() {
+			exposedChanges = make(chan event.GenericEvent)
+			outs = append(outs, exposedChanges)
+		}
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			broadcastConfigChanges(ctx, r.ConfigChanges, outs...)
+			return nil
+		})); err != nil {
+			return err
+		}
+		// A new configuration may change the route of every exposed Service
+		b = b.WatchesRawSource(source.Channel(serviceChanges,
+			handler.EnqueueRequestsFromMapFunc(r.requestsForExposedServices)))
+	}
+	if err := b.Named("service").Complete(r); err != nil {
+		return err
+	}
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&httproutev1alpha1.ExposurePolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Named("exposurepolicy").
+		Complete(reconcile.Func(r.reconcileExposurePolicy)); err != nil {
+		return err
+	}
+
+	if !r.handlesResources() {
+		return nil
+	}
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&httproutev1alpha1.ExposureRule{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.requestsForServiceRules)).
+		Named("exposurerule").
+		Complete(reconcile.Func(r.reconcileExposureRule)); err != nil {
+		return err
+	}
+	eb := ctrl.NewControllerManagedBy(mgr).
+		For(&httproutev1alpha1.ExposedService{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&gatewayv1beta1.ReferenceGrant{}).
+		Watches(&gatewayv1.HTTPRoute{}, handler.EnqueueRequestsFromMapFunc(r.requestForExposedResource)).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.requestsForServiceExposures)).
+		Watches(&corev1.Service{}, r.hostnameClaimHandler(true)).
+		Watches(&httproutev1alpha1.ExposedService{}, r.hostnameClaimHandler(true)).
+		Watches(&httproutev1alpha1.ExposurePolicy{}, handler.EnqueueRequestsFromMapFunc(r.requestsForAllExposures),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&gatewayv1.Gateway{}, handler.EnqueueRequestsFromMapFunc(r.requestsForGatewayExposures),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, gatewayAddressesChanged)))
+	if exposedChanges != nil {
+		// ExposedServices take their gateway and listener defaults from the configuration too
+		eb = eb.WatchesRawSource(source.Channel(exposedChanges,
+			handler.EnqueueRequestsFromMapFunc(r.requestsForAllExposures)))
+	}
+	return eb.Named("exposedservice").Complete(reconcile.Func(r.reconcileExposedService))
 }
@@ -56,7 +56,7 @@ var _ = Describe("Service Controller", func() {
 			// ASSERT: HTTPRoute should NOT exist
 			route := &gatewayv1.HTTPRoute{}
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-no-expose",
+				Name:      routeName("default", "test-svc-no-expose"),
 				Namespace: "envoy-gateway-system",
 			}
 			err := k8sClient.Get(ctx, routeKey, route)
@@ -65,7 +65,7 @@ var _ = Describe("Service Controller", func() {
 			// ASSERT: ReferenceGrant should NOT exist
 			grant := &gatewayv1beta1.ReferenceGrant{}
 			grantKey := types.NamespacedName{
-				Name:      "test-svc-no-expose-backend",
+				Name:      referenceGrantName("default", "test-svc-no-expose"),
 				Namespace: "default",
 			}
 			err = k8sClient.Get(ctx, grantKey, grant)
@@ -105,7 +105,7 @@ var _ = Describe("Service Controller", func() {
 			// ASSERT: HTTPRoute should NOT exist
 			route := &gatewayv1.HTTPRoute{}
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-expose-false",
+				Name:      routeName("default", "test-svc-expose-false"),
 				Namespace: "envoy-gateway-system",
 			}
 			err := k8sClient.Get(ctx, routeKey, route)
@@ -143,7 +143,7 @@ var _ = Describe("Service Controller", func() {
 			// ACT & ASSERT: HTTPRoute should be created
 			route := &gatewayv1.HTTPRoute{}
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-exposed",
+				Name:      routeName("default", "test-svc-exposed"),
 				Namespace: "envoy-gateway-system",
 			}
 			Eventually(func() bool {
@@ -169,7 +169,7 @@ var _ = Describe("Service Controller", func() {
 			// ACT & ASSERT: ReferenceGrant should be created
 			grant := &gatewayv1beta1.ReferenceGrant{}
 			grantKey := types.NamespacedName{
-				Name:      "test-svc-exposed-backend",
+				Name:      referenceGrantName("default", "test-svc-exposed"),
 				Namespace: "default",
 			}
 			Eventually(func() bool {
@@ -225,7 +225,7 @@ var _ = Describe("Service Controller", func() {
 			// ACT & ASSERT: HTTPRoute should be created in custom namespace
 			route := &gatewayv1.HTTPRoute{}
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-custom-gw",
+				Name:      routeName("default", "test-svc-custom-gw"),
 				Namespace: "custom-ns",
 			}
 			Eventually(func() bool {
@@ -240,7 +240,7 @@ var _ = Describe("Service Controller", func() {
 			// ASSERT: ReferenceGrant should allow access from custom namespace
 			grant := &gatewayv1beta1.ReferenceGrant{}
 			grantKey := types.NamespacedName{
-				Name:      "test-svc-custom-gw-backend",
+				Name:      referenceGrantName("default", "test-svc-custom-gw"),
 				Namespace: "default",
 			}
 			Eventually(func() bool {
@@ -281,7 +281,7 @@ var _ = Describe("Service Controller", func() {
 
 			// Wait for initial HTTPRoute
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-update",
+				Name:      routeName("default", "test-svc-update"),
 				Namespace: "envoy-gateway-system",
 			}
 			route := &gatewayv1.HTTPRoute{}
@@ -336,7 +336,7 @@ var _ = Describe("Service Controller", func() {
 
 			// Wait for resources to be created
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-remove-expose",
+				Name:      routeName("default", "test-svc-remove-expose"),
 				Namespace: "envoy-gateway-system",
 			}
 			route := &gatewayv1.HTTPRoute{}
@@ -391,7 +391,7 @@ var _ = Describe("Service Controller", func() {
 			// ASSERT: HTTPRoute should NOT exist (invalid configuration)
 			route := &gatewayv1.HTTPRoute{}
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-no-hostname",
+				Name:      routeName("default", "test-svc-no-hostname"),
 				Namespace: "envoy-gateway-system",
 			}
 			err := k8sClient.Get(ctx, routeKey, route)
@@ -430,7 +430,7 @@ var _ = Describe("Service Controller", func() {
 			// ACT & ASSERT: HTTPRoute should be created
 			route := &gatewayv1.HTTPRoute{}
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-skip-grant",
+				Name:      routeName("default", "test-svc-skip-grant"),
 				Namespace: "envoy-gateway-system",
 			}
 			Eventually(func() bool {
@@ -442,7 +442,7 @@ var _ = Describe("Service Controller", func() {
 			time.Sleep(2 * time.Second) // Give controller time to potentially create it
 			grant := &gatewayv1beta1.ReferenceGrant{}
 			grantKey := types.NamespacedName{
-				Name:      "test-svc-skip-grant-backend",
+				Name:      referenceGrantName("default", "test-svc-skip-grant"),
 				Namespace: "default",
 			}
 			err := k8sClient.Get(ctx, grantKey, grant)
@@ -481,11 +481,11 @@ var _ = Describe("Service Controller", func() {
 
 			// Wait for resources to be created
 			routeKey := types.NamespacedName{
-				Name:      "default-test-svc-delete",
+				Name:      routeName("default", "test-svc-delete"),
 				Namespace: "envoy-gateway-system",
 			}
 			grantKey := types.NamespacedName{
-				Name:      "test-svc-delete-backend",
+				Name:      referenceGrantName("default", "test-svc-delete"),
 				Namespace: "default",
 			}
 
@@ -304,9 +304,9 @@ spec:
 			By("verifying HTTPRoute is created in gateway namespace")
 			verifyHTTPRoute := func(g Gomega) {
 				cmd := exec.Command("kubectl", "get", "httproute",
-					fmt.Sprintf("%s-%s", testNamespace, testServiceName),
+					"-l", sourceSelector(testNamespace, testServiceName),
 					"-n", gatewayNamespace,
-					"-o", "jsonpath={.spec.hostnames[0]}")
+					"-o", "jsonpath={.items[0].spec.hostnames[0]}")
 				output, err := utils.Run(cmd)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(output).To(Equal("test-e2e.example.com"))
@@ -316,10 +316,12 @@ spec:
 			By("verifying ReferenceGrant is created in service namespace")
 			verifyReferenceGrant := func(g Gomega) {
 				cmd := exec.Command("kubectl", "get", "referencegrant",
-					fmt.Sprintf("%s-backend", testServiceName),
-					"-n", testNamespace)
-				_, err := utils.Run(cmd)
+					"-l", sourceSelector(testNamespace, testServiceName),
+					"-n", testNamespace,
+					"-o", "name")
+				output, err := utils.Run(cmd)
 				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(output).NotTo(BeEmpty())
 			}
 			Eventually(verifyReferenceGrant, 30*time.Second, time.Second).Should(Succeed())
 
@@ -340,10 +342,12 @@ spec:
 			By("verifying HTTPRoute is deleted")
 			verifyHTTPRouteDeleted := func(g Gomega) {
 				cmd := exec.Command("kubectl", "get", "httproute",
-					fmt.Sprintf("%s-%s", testNamespace, testServiceName),
-					"-n", gatewayNamespace)
-				_, err := utils.Run(cmd)
-				g.Expect(err).To(HaveOccurred()) // Should error because not found
+					"-l", sourceSelector(testNamespace, testServiceName),
+					"-n", gatewayNamespace,
+					"-o", "name")
+				output, err := utils.Run(cmd)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(output).To(BeEmpty())
 			}
 			Eventually(verifyHTTPRouteDeleted, 30*time.Second, time.Second).Should(Succeed())
 		})
@@ -376,9 +380,9 @@ spec:
 			By("verifying HTTPRoute uses custom section name")
 			verifySectionName := func(g Gomega) {
 				cmd := exec.Command("kubectl", "get", "httproute",
-					fmt.Sprintf("%s-%s-section", testNamespace, testServiceName),
+					"-l", sourceSelector(testNamespace, testServiceName+"-section"),
 					"-n", gatewayNamespace,
-					"-o", "jsonpath={.spec.parentRefs[0].sectionName}")
+					"-o", "jsonpath={.items[0].spec.parentRefs[0].sectionName}")
 				output, err := utils.Run(cmd)
 				g.Expect(err).NotTo(HaveOccurred())
 				g.Expect(output).To(Equal("http"))
@@ -392,6 +396,11 @@ spec:
 	})
 })
 
+// sourceSelector returns the label selector matching resources generated for a Service.
+func sourceSelector(namespace, name string) string {
+	return fmt.Sprintf("httproute.controller/service-namespace=%s,httproute.controller/service-name=%s", namespace, name)
+}
+
 // serviceAccountToken returns a token for the specified service account in the given namespace.
 // It uses the Kubernetes TokenRequest API to generate a token by directly sending a request
 // and parsing the resulting token from the API response.